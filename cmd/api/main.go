@@ -2,8 +2,9 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,53 +13,158 @@ import (
 
 	"feedback/internal/config"
 	"feedback/internal/db"
+	"feedback/internal/health"
+	"feedback/internal/logging"
+	"feedback/internal/mail"
+	"feedback/internal/metrics"
+	"feedback/internal/middleware"
 	"feedback/internal/modules/auth"
 	"feedback/internal/modules/feedback"
+	"feedback/internal/storage"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Configuration error: %v", err)
+		fatal(slog.Default(), "configuration error", err)
 	}
 
+	// Structured logger shared by every module; records carry the request and user IDs
+	logger := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	autoMigrate := flag.Bool("auto-migrate", cfg.AutoMigrate, "apply pending database migrations on startup (default from AUTO_MIGRATE)")
+	flag.Parse()
+
 	// Initialize database pool
 	ctx := context.Background()
 	pool, err := db.InitPool(ctx, cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("Database connection error: %v", err)
+		fatal(logger, "database connection error", err)
 	}
 	defer pool.Close()
 
-	log.Println("Database connection established")
+	logger.Info("database connection established")
+
+	// Apply pending migrations (advisory-locked, safe with several instances starting at once)
+	if *autoMigrate {
+		applied, err := db.MigrateUp(ctx, pool)
+		if err != nil {
+			fatal(logger, "database migration error", err)
+		}
+		logger.Info("database migrations up to date", "applied", len(applied))
+	}
 
 	// Create HTTP mux
 	mux := http.NewServeMux()
 
-	// Health endpoint
+	// Liveness and readiness probes; readiness needs Postgres and the migrated schema
+	checker := health.New(2*time.Second, logger.With("module", "health"),
+		health.Database(pool),
+		health.Tables(pool, "users", "login_links", "feedback"),
+	)
+	mux.HandleFunc("GET /livez", checker.Live)
+	mux.HandleFunc("GET /readyz", checker.Ready)
+
+	// Legacy liveness endpoint kept for existing monitors
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	// Register auth routes (Mailgun mail config)
-	mailConfig := auth.MailConfig{
-		APIKey:  cfg.MailgunAPIKey,
-		Domain:  cfg.MailgunDomain,
-		BaseURL: cfg.MailgunBaseURL,
+	// Prometheus metrics: per-route latency, DB pool stats, email and Slack outcomes
+	metrics.RegisterPool(pool)
+	mux.Handle("GET /metrics", metrics.Handler(cfg.MetricsToken))
+
+	// Session revocation checks for RequireAuth, cached in memory and shared by all modules
+	sessions := middleware.NewSessionCache(auth.NewRepository(pool, logger), cfg.SessionCacheTTL)
+
+	// Mailer selected by MAIL_BACKEND (Mailgun, SMTP, .eml files or log)
+	mailer, err := mail.New(mail.Config{
+		Backend: cfg.MailBackend,
 		From:    cfg.EmailFrom,
+		Mailgun: mail.MailgunConfig{
+			APIKey:  cfg.MailgunAPIKey,
+			Domain:  cfg.MailgunDomain,
+			BaseURL: cfg.MailgunBaseURL,
+		},
+		SMTP: mail.SMTPConfig{
+			Host:          cfg.SMTPHost,
+			Port:          cfg.SMTPPort,
+			Username:      cfg.SMTPUsername,
+			Password:      cfg.SMTPPassword,
+			AllowInsecure: cfg.SMTPAllowInsecure,
+		},
+		Dir:    cfg.MailFileDir,
+		Logger: logger,
+	})
+	if err != nil {
+		fatal(logger, "mailer error", err)
+	}
+	logger.Info("mail backend configured", "backend", cfg.MailBackend)
+
+	// Embedded, localized email templates with branding from config
+	mailTemplates, err := mail.NewTemplates(mail.Branding{
+		AppName:      cfg.BrandAppName,
+		PrimaryColor: cfg.BrandPrimaryColor,
+		TextColor:    cfg.BrandTextColor,
+		SupportEmail: cfg.BrandSupportEmail,
+	}, cfg.MailDefaultLocale)
+	if err != nil {
+		fatal(logger, "email template error", err)
 	}
-	auth.RegisterRoutes(mux, pool, cfg.JWTSecret, cfg.AppDeeplinkURL, mailConfig)
+
+	// Register auth routes
+	auth.RegisterRoutes(mux, pool, cfg.JWTSecret, cfg.AppDeeplinkURL, mailer, mailTemplates, auth.TokenConfig{
+		AccessTTL:  cfg.AccessTokenTTL,
+		RefreshTTL: cfg.RefreshTokenTTL,
+	}, sessions, loginLinkRateLimits(cfg, pool, logger), logger)
+
+	// Blob storage for feedback attachments
+	store, err := storage.New(storage.Config{
+		Backend: cfg.StorageBackend,
+		Dir:     cfg.StorageDir,
+	})
+	if err != nil {
+		fatal(logger, "storage error", err)
+	}
+	logger.Info("storage backend configured", "backend", cfg.StorageBackend)
 
 	// Register feedback routes
-	feedback.RegisterRoutes(mux, pool, cfg.JWTSecret)
+	feedback.RegisterRoutes(mux, pool, cfg.JWTSecret, sessions, store, mailer, mailTemplates, feedback.Config{
+		EditWindow:      cfg.FeedbackEditWindow,
+		MaxMessageRunes: cfg.FeedbackMaxMessageRunes,
+		MaxBodyBytes:    cfg.FeedbackMaxBodyBytes,
+
+		AttachmentMaxBytes: cfg.AttachmentMaxBytes,
+		MaxAttachments:     cfg.AttachmentMaxPerFeedback,
+		AttachmentURLTTL:   cfg.AttachmentURLTTL,
+		PublicBaseURL:      cfg.PublicBaseURL,
+
+		DeeplinkURL: cfg.AppDeeplinkURL,
+	}, logger)
+
+	// Start Slack outbox dispatcher (Slack client selected from config)
+	slackClient := feedback.NewSlackClient(feedback.SlackConfig{
+		WebhookURL: cfg.SlackWebhookURL,
+		BotToken:   cfg.SlackBotToken,
+		Channel:    cfg.SlackChannel,
+		APIBaseURL: cfg.SlackAPIBaseURL,
+	}, logger)
+	dispatcher := feedback.NewDispatcher(pool, slackClient, feedback.DispatcherConfig{
+		PollInterval: cfg.SlackOutboxPollInterval,
+		MaxAttempts:  cfg.SlackOutboxMaxAttempts,
+	}, logger)
+	dispatcher.Start()
 
 	// Create server (Render provides PORT as string)
 	addr := fmt.Sprintf(":%s", cfg.Port)
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      middleware.RequestID(middleware.AccessLog(logger)(metrics.Middleware(mux))),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -66,9 +172,9 @@ func main() {
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("Server starting on %s", addr)
+		logger.Info("server starting", "addr", addr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v", err)
+			fatal(logger, "server error", err)
 		}
 	}()
 
@@ -77,13 +183,44 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Server shutting down...")
+	// Fail readiness first so load balancers stop routing here, then stop the server
+	checker.SetShuttingDown()
+	logger.Info("server draining", "delay", cfg.ShutdownDrainDelay.String())
+	time.Sleep(cfg.ShutdownDrainDelay)
+
+	logger.Info("server shutting down")
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal(logger, "server forced to shutdown", err)
 	}
 
-	log.Println("Server stopped")
+	// Drain the outbox after the server so events from in-flight requests are delivered
+	if err := dispatcher.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("slack outbox dispatcher did not drain", "error", err)
+	}
+
+	logger.Info("server stopped")
+}
+
+// fatal logs err and exits.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// loginLinkRateLimits builds the login-link rate limits, sharing buckets through
+// Postgres when RATE_LIMIT_STORE=postgres.
+func loginLinkRateLimits(cfg config.Config, pool *pgxpool.Pool, logger *slog.Logger) auth.RateLimitConfig {
+	limits := auth.RateLimitConfig{
+		PerEmail:          auth.RateLimit{Burst: cfg.LoginLinkEmailLimit, Per: cfg.LoginLinkEmailWindow},
+		PerIP:             auth.RateLimit{Burst: cfg.LoginLinkIPLimit, Per: cfg.LoginLinkIPWindow},
+		TrustProxyHeaders: cfg.TrustProxyHeaders,
+	}
+	if cfg.RateLimitStore == "postgres" {
+		retention := max(cfg.LoginLinkEmailWindow, cfg.LoginLinkIPWindow)
+		limits.Store = auth.NewPostgresRateLimitStore(pool, retention, logger.With("module", "auth"))
+	}
+	return limits
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"feedback/internal/db"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [N]    revert the last N applied migrations (default 1)
+  status      list migrations and when they were applied
+
+Reads DATABASE_URL from the environment.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Only the database is needed, so don't go through config.Load (which requires mail settings).
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	pool, err := db.InitPool(ctx, databaseURL)
+	if err != nil {
+		log.Fatalf("Database connection error: %v", err)
+	}
+	defer pool.Close()
+
+	switch cmd := os.Args[1]; cmd {
+	case "up":
+		applied, err := db.MigrateUp(ctx, pool)
+		for _, m := range applied {
+			log.Printf("Applied %03d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migrate up failed: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid step count %q", os.Args[2])
+			}
+		}
+		reverted, err := db.MigrateDown(ctx, pool, steps)
+		for _, m := range reverted {
+			log.Printf("Reverted %03d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migrate down failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			log.Println("No applied migrations to revert")
+		}
+
+	case "status":
+		statuses, err := db.MigrationStatuses(ctx, pool)
+		if err != nil {
+			log.Fatalf("Migrate status failed: %v", err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(tw, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		tw.Flush()
+
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", cmd, usage)
+		os.Exit(2)
+	}
+}
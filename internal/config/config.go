@@ -2,19 +2,76 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	Port           string
 	DatabaseURL    string
+	AutoMigrate    bool
 	JWTSecret      string
 	AppDeeplinkURL string
 
-	MailgunAPIKey  string
-	MailgunDomain  string
-	MailgunBaseURL string
-	EmailFrom      string
+	LogLevel  string
+	LogFormat string
+
+	MetricsToken string
+
+	ShutdownDrainDelay time.Duration
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	SessionCacheTTL time.Duration
+
+	LoginLinkEmailLimit  int
+	LoginLinkEmailWindow time.Duration
+	LoginLinkIPLimit     int
+	LoginLinkIPWindow    time.Duration
+	RateLimitStore       string
+	TrustProxyHeaders    bool
+
+	MailBackend       string
+	EmailFrom         string
+	MailgunAPIKey     string
+	MailgunDomain     string
+	MailgunBaseURL    string
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string
+	SMTPAllowInsecure bool
+	MailFileDir       string
+
+	MailDefaultLocale string
+	BrandAppName      string
+	BrandPrimaryColor string
+	BrandTextColor    string
+	BrandSupportEmail string
+
+	SlackWebhookURL string
+	SlackBotToken   string
+	SlackChannel    string
+	SlackAPIBaseURL string
+
+	SlackOutboxMaxAttempts  int
+	SlackOutboxPollInterval time.Duration
+
+	FeedbackEditWindow      time.Duration
+	FeedbackMaxMessageRunes int
+	FeedbackMaxBodyBytes    int64
+
+	StorageBackend           string
+	StorageDir               string
+	AttachmentMaxBytes       int64
+	AttachmentMaxPerFeedback int
+	AttachmentURLTTL         time.Duration
+	PublicBaseURL            string
 }
 
 func Load() (Config, error) {
@@ -29,21 +86,156 @@ func Load() (Config, error) {
 		mailgunBaseURL = "https://api.mailgun.net"
 	}
 
+	// Default to the public Slack Web API if not set
+	slackAPIBaseURL := os.Getenv("SLACK_API_BASE_URL")
+	if slackAPIBaseURL == "" {
+		slackAPIBaseURL = "https://slack.com/api"
+	}
+
 	cfg := Config{
 		Port:           port,
 		DatabaseURL:    mustEnv("DATABASE_URL"),
+		AutoMigrate:    envBool("AUTO_MIGRATE", false),
 		JWTSecret:      mustEnv("JWT_SECRET"),
 		AppDeeplinkURL: mustEnv("APP_DEEPLINK_URL"),
 
-		MailgunAPIKey:  mustEnv("MAILGUN_API_KEY"),
-		MailgunDomain:  mustEnv("MAILGUN_DOMAIN"), // e.g. sandboxXXXX.mailgun.org
-		MailgunBaseURL: mailgunBaseURL,
-		EmailFrom:      mustEnv("EMAIL_FROM"),
+		// Structured logs: json (default) or text, at debug, info, warn or error
+		LogLevel:  envString("LOG_LEVEL", "info"),
+		LogFormat: envString("LOG_FORMAT", "json"),
+
+		// Optional bearer token required by GET /metrics
+		MetricsToken: os.Getenv("METRICS_TOKEN"),
+
+		// How long /readyz reports not-ready before the server stops accepting connections
+		ShutdownDrainDelay: envDuration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+
+		AccessTokenTTL:  envDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: envDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		// How long a session's revocation state is cached per instance
+		SessionCacheTTL: envDuration("SESSION_CACHE_TTL", 30*time.Second),
+
+		// Token buckets on POST /auth/login-link; a limit of 0 disables it
+		LoginLinkEmailLimit:  envInt("LOGIN_LINK_EMAIL_LIMIT", 5),
+		LoginLinkEmailWindow: envDuration("LOGIN_LINK_EMAIL_WINDOW", time.Hour),
+		LoginLinkIPLimit:     envInt("LOGIN_LINK_IP_LIMIT", 20),
+		LoginLinkIPWindow:    envDuration("LOGIN_LINK_IP_WINDOW", time.Hour),
+		RateLimitStore:       envString("RATE_LIMIT_STORE", "memory"),
+		TrustProxyHeaders:    envBool("TRUST_PROXY_HEADERS", false),
+
+		// Mail backend: mailgun, smtp, file (.eml files) or log
+		MailBackend:       os.Getenv("MAIL_BACKEND"),
+		EmailFrom:         envString("EMAIL_FROM", "FeedbackApp <no-reply@localhost>"),
+		MailgunAPIKey:     os.Getenv("MAILGUN_API_KEY"),
+		MailgunDomain:     os.Getenv("MAILGUN_DOMAIN"), // e.g. sandboxXXXX.mailgun.org
+		MailgunBaseURL:    mailgunBaseURL,
+		SMTPHost:          os.Getenv("SMTP_HOST"),
+		SMTPPort:          envInt("SMTP_PORT", 587),
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		SMTPAllowInsecure: envBool("SMTP_ALLOW_INSECURE", false),
+		MailFileDir:       envString("MAIL_FILE_DIR", "tmp/mail"),
+
+		// Email templates: locale fallback and branding
+		MailDefaultLocale: envString("MAIL_DEFAULT_LOCALE", "en"),
+		BrandAppName:      envString("BRAND_APP_NAME", "FeedbackApp"),
+		BrandPrimaryColor: envString("BRAND_PRIMARY_COLOR", "#4f46e5"),
+		BrandTextColor:    envString("BRAND_TEXT_COLOR", "#222222"),
+		BrandSupportEmail: os.Getenv("BRAND_SUPPORT_EMAIL"),
+
+		// Slack is optional: webhook wins over bot token; neither means mock client
+		SlackWebhookURL: os.Getenv("SLACK_WEBHOOK_URL"),
+		SlackBotToken:   os.Getenv("SLACK_BOT_TOKEN"),
+		SlackChannel:    os.Getenv("SLACK_CHANNEL"),
+		SlackAPIBaseURL: slackAPIBaseURL,
+
+		// Outbox delivery: retries with exponential backoff, then dead-letters
+		SlackOutboxMaxAttempts:  envInt("SLACK_OUTBOX_MAX_ATTEMPTS", 8),
+		SlackOutboxPollInterval: envDuration("SLACK_OUTBOX_POLL_INTERVAL", 5*time.Second),
+
+		FeedbackEditWindow:      envDuration("FEEDBACK_EDIT_WINDOW", 15*time.Minute),
+		FeedbackMaxMessageRunes: envInt("FEEDBACK_MAX_MESSAGE_RUNES", 4000),
+		FeedbackMaxBodyBytes:    int64(envInt("FEEDBACK_MAX_BODY_BYTES", 64<<10)),
+
+		// Attachment blobs: local disk for now; download URLs are signed and expire
+		StorageBackend:           envString("STORAGE_BACKEND", "local"),
+		StorageDir:               envString("STORAGE_DIR", "data/attachments"),
+		AttachmentMaxBytes:       int64(envInt("ATTACHMENT_MAX_BYTES", 5<<20)),
+		AttachmentMaxPerFeedback: envInt("ATTACHMENT_MAX_PER_FEEDBACK", 5),
+		AttachmentURLTTL:         envDuration("ATTACHMENT_URL_TTL", 15*time.Minute),
+		// Public origin of this API (e.g. https://api.example.com), used in download URLs
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+	}
+
+	switch cfg.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return Config{}, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", cfg.LogLevel)
+	}
+	if cfg.LogFormat != "json" && cfg.LogFormat != "text" {
+		return Config{}, fmt.Errorf("LOG_FORMAT must be json or text, got %q", cfg.LogFormat)
+	}
+
+	// Without MAIL_BACKEND, keep using Mailgun as before. Never fall back to a backend that
+	// does not deliver: a deploy missing its mail settings must fail here, not at login.
+	if cfg.MailBackend == "" {
+		if cfg.MailgunAPIKey == "" {
+			return Config{}, fmt.Errorf("MAIL_BACKEND is not set and MAILGUN_API_KEY is missing; set MAIL_BACKEND=log or file for local development")
+		}
+		cfg.MailBackend = "mailgun"
+	}
+	switch cfg.MailBackend {
+	case "mailgun":
+		if cfg.MailgunAPIKey == "" || cfg.MailgunDomain == "" || os.Getenv("EMAIL_FROM") == "" {
+			return Config{}, fmt.Errorf("MAILGUN_API_KEY, MAILGUN_DOMAIN and EMAIL_FROM are required when MAIL_BACKEND=mailgun")
+		}
+	case "smtp":
+		if cfg.SMTPHost == "" || os.Getenv("EMAIL_FROM") == "" {
+			return Config{}, fmt.Errorf("SMTP_HOST and EMAIL_FROM are required when MAIL_BACKEND=smtp")
+		}
+		// Plaintext is only for a relay on this machine (e.g. a local mail catcher)
+		if cfg.SMTPAllowInsecure && !loopbackHost(cfg.SMTPHost) {
+			return Config{}, fmt.Errorf("SMTP_ALLOW_INSECURE is only allowed with a loopback SMTP_HOST, got %q", cfg.SMTPHost)
+		}
+	case "file", "log":
+	default:
+		return Config{}, fmt.Errorf("MAIL_BACKEND must be mailgun, smtp, file or log, got %q", cfg.MailBackend)
+	}
+
+	for key, color := range map[string]string{"BRAND_PRIMARY_COLOR": cfg.BrandPrimaryColor, "BRAND_TEXT_COLOR": cfg.BrandTextColor} {
+		if !hexColor.MatchString(color) {
+			return Config{}, fmt.Errorf("%s must be a hex color like #4f46e5, got %q", key, color)
+		}
+	}
+
+	if cfg.SlackWebhookURL == "" && cfg.SlackBotToken != "" && cfg.SlackChannel == "" {
+		return Config{}, fmt.Errorf("SLACK_CHANNEL is required when SLACK_BOT_TOKEN is set")
+	}
+
+	if cfg.RateLimitStore != "memory" && cfg.RateLimitStore != "postgres" {
+		return Config{}, fmt.Errorf("RATE_LIMIT_STORE must be memory or postgres, got %q", cfg.RateLimitStore)
+	}
+
+	if cfg.FeedbackEditWindow <= 0 {
+		return Config{}, fmt.Errorf("FEEDBACK_EDIT_WINDOW must be positive, got %s", cfg.FeedbackEditWindow)
+	}
+
+	// Email links are built from APP_DEEPLINK_URL and marked safe for the HTML emails
+	if u, err := url.Parse(cfg.AppDeeplinkURL); err != nil || u.Scheme == "" {
+		return Config{}, fmt.Errorf("APP_DEEPLINK_URL must be an absolute URL such as https://api.example.com/auth/deeplink or feedbackapp://auth, got %q", cfg.AppDeeplinkURL)
+	} else if scheme := strings.ToLower(u.Scheme); scheme == "javascript" || scheme == "vbscript" || scheme == "data" || scheme == "file" {
+		return Config{}, fmt.Errorf("APP_DEEPLINK_URL must not use the %s: scheme", scheme)
+	}
+
+	if cfg.StorageBackend != "local" {
+		return Config{}, fmt.Errorf("STORAGE_BACKEND must be local, got %q", cfg.StorageBackend)
 	}
 
 	return cfg, nil
 }
 
+// hexColor matches CSS hex colors (#rgb or #rrggbb) allowed in email branding.
+var hexColor = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
 func mustEnv(key string) string {
 	v := os.Getenv(key)
 	if v == "" {
@@ -51,3 +243,59 @@ func mustEnv(key string) string {
 	}
 	return v
 }
+
+// envString reads a string env var, falling back to def when unset.
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envInt reads an integer env var, falling back to def when unset.
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be an integer: %v", key, err))
+	}
+	return n
+}
+
+// envDuration reads a time.ParseDuration env var (e.g. "5s"), falling back to def when unset.
+func envDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be a duration: %v", key, err))
+	}
+	return d
+}
+
+// envBool reads a strconv.ParseBool env var (e.g. "true", "1"), falling back to def when unset.
+// loopbackHost reports whether host is localhost or a loopback IP address.
+func loopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func envBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		panic(fmt.Sprintf("%s must be a boolean: %v", key, err))
+	}
+	return b
+}
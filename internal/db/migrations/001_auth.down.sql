@@ -0,0 +1,3 @@
+-- Drop auth tables
+DROP TABLE IF EXISTS login_links;
+DROP TABLE IF EXISTS users;
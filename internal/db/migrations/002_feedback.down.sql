@@ -0,0 +1,2 @@
+-- Drop feedback table
+DROP TABLE IF EXISTS feedback;
@@ -0,0 +1,2 @@
+-- Drop slack_outbox table
+DROP TABLE IF EXISTS slack_outbox;
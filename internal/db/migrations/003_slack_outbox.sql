@@ -0,0 +1,18 @@
+-- Create slack_outbox table (transactional outbox for Slack notifications)
+CREATE TABLE slack_outbox (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID REFERENCES feedback(id) ON DELETE SET NULL,
+  event TEXT NOT NULL,
+  payload JSONB NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'dead')),
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_until TIMESTAMPTZ,
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  sent_at TIMESTAMPTZ
+);
+
+-- Create indexes
+CREATE INDEX idx_slack_outbox_due ON slack_outbox(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_slack_outbox_feedback_id ON slack_outbox(feedback_id);
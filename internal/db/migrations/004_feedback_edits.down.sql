@@ -0,0 +1,3 @@
+-- Drop feedback edit tracking
+DROP TABLE IF EXISTS feedback_edits;
+ALTER TABLE feedback DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,13 @@
+-- Track edits on feedback
+ALTER TABLE feedback ADD COLUMN updated_at TIMESTAMPTZ;
+
+-- Create feedback_edits table (previous versions of edited feedback)
+CREATE TABLE feedback_edits (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+  previous_message TEXT NOT NULL,
+  edited_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create indexes
+CREATE INDEX idx_feedback_edits_feedback_id ON feedback_edits(feedback_id, edited_at);
@@ -0,0 +1,2 @@
+-- Drop feedback message length cap
+ALTER TABLE feedback DROP CONSTRAINT IF EXISTS feedback_message_length;
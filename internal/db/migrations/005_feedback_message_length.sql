@@ -0,0 +1,7 @@
+-- Cap feedback message length (characters, not bytes).
+-- Must stay >= FEEDBACK_MAX_MESSAGE_RUNES; see MaxMessageRunesLimit in feedback.service.go.
+-- NOT VALID: new and updated rows are checked, but rows written before the API had a limit
+-- are not, so this cannot fail on existing data. Clean those up and run VALIDATE CONSTRAINT
+-- by hand (docs/DATABASE_SCHEMA.md, "Validating feedback_message_length").
+ALTER TABLE feedback
+  ADD CONSTRAINT feedback_message_length CHECK (char_length(message) <= 4000) NOT VALID;
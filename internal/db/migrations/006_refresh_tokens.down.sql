@@ -0,0 +1,2 @@
+-- Drop refresh tokens
+DROP TABLE IF EXISTS refresh_tokens;
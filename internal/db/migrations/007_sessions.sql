@@ -0,0 +1,23 @@
+-- Create sessions table (one row per login; access tokens carry its ID as the sid claim)
+CREATE TABLE sessions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  revoked_at TIMESTAMPTZ
+);
+
+-- Existing refresh token families become sessions
+INSERT INTO sessions (id, user_id, created_at, revoked_at)
+SELECT family_id,
+       min(user_id::text)::uuid,
+       min(created_at),
+       CASE WHEN bool_and(revoked_at IS NOT NULL) THEN max(revoked_at) END
+FROM refresh_tokens
+GROUP BY family_id;
+
+ALTER TABLE refresh_tokens
+  ADD CONSTRAINT refresh_tokens_family_id_fkey
+  FOREIGN KEY (family_id) REFERENCES sessions(id) ON DELETE CASCADE;
+
+-- Create indexes
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
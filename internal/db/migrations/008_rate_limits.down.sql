@@ -0,0 +1,2 @@
+-- Drop rate limit buckets
+DROP TABLE IF EXISTS rate_limit_buckets;
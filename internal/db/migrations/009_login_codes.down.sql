@@ -0,0 +1,4 @@
+-- Drop login codes
+DROP INDEX IF EXISTS idx_login_links_user_id_created_at;
+ALTER TABLE login_links DROP COLUMN IF EXISTS code_attempts;
+ALTER TABLE login_links DROP COLUMN IF EXISTS code_hash;
@@ -0,0 +1,6 @@
+-- One-time numeric login codes, issued alongside a magic link
+ALTER TABLE login_links ADD COLUMN code_hash TEXT;
+ALTER TABLE login_links ADD COLUMN code_attempts INT NOT NULL DEFAULT 0;
+
+-- Create indexes
+CREATE INDEX idx_login_links_user_id_created_at ON login_links(user_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Drop login link verifier
+ALTER TABLE login_links DROP COLUMN IF EXISTS verifier_hash;
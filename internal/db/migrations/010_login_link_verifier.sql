@@ -0,0 +1,2 @@
+-- Bind login links to the requesting device: SHA-256 of a client-held verifier (PKCE S256)
+ALTER TABLE login_links ADD COLUMN verifier_hash TEXT;
@@ -0,0 +1,8 @@
+-- Drop feedback category, rating and client metadata
+ALTER TABLE feedback
+  DROP COLUMN IF EXISTS metadata,
+  DROP COLUMN IF EXISTS os_version,
+  DROP COLUMN IF EXISTS platform,
+  DROP COLUMN IF EXISTS app_version,
+  DROP COLUMN IF EXISTS rating,
+  DROP COLUMN IF EXISTS category;
@@ -0,0 +1,9 @@
+-- Feedback category, rating and client metadata. All optional: rows created before this
+-- migration (and clients that send none) keep NULLs and an empty context map.
+ALTER TABLE feedback
+  ADD COLUMN category TEXT CHECK (category IN ('bug', 'idea', 'praise')),
+  ADD COLUMN rating SMALLINT CHECK (rating BETWEEN 1 AND 5),
+  ADD COLUMN app_version TEXT,
+  ADD COLUMN platform TEXT,
+  ADD COLUMN os_version TEXT,
+  ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
@@ -0,0 +1,2 @@
+-- Drop feedback attachments (blobs in storage are left in place)
+DROP TABLE IF EXISTS feedback_attachments;
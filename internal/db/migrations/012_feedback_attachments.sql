@@ -0,0 +1,13 @@
+-- Files (e.g. screenshots) attached to feedback. The bytes live in blob storage under
+-- storage_key; rows go with their feedback, the blobs are removed by the application.
+CREATE TABLE feedback_attachments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+  storage_key TEXT NOT NULL UNIQUE,
+  filename TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_feedback_attachments_feedback_id ON feedback_attachments(feedback_id);
@@ -0,0 +1,4 @@
+-- Drop user roles and feedback status
+DROP INDEX IF EXISTS idx_feedback_status_created_at;
+ALTER TABLE feedback DROP COLUMN IF EXISTS status;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
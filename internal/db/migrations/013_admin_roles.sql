@@ -0,0 +1,12 @@
+-- User roles and feedback status for the admin inbox. Every existing user stays a plain
+-- user (promote with UPDATE users SET role = 'admin' WHERE email = ...) and all existing
+-- feedback starts as new.
+ALTER TABLE users
+  ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin'));
+
+ALTER TABLE feedback
+  ADD COLUMN status TEXT NOT NULL DEFAULT 'new'
+    CHECK (status IN ('new', 'triaged', 'in_progress', 'resolved', 'wont_fix'));
+
+-- Admin inbox filtered by status, newest first
+CREATE INDEX idx_feedback_status_created_at ON feedback(status, created_at DESC, id DESC);
@@ -0,0 +1,5 @@
+-- Drop the triage workflow tables and feedback.assignee_id
+DROP TABLE IF EXISTS feedback_audit;
+DROP TABLE IF EXISTS feedback_notes;
+DROP INDEX IF EXISTS idx_feedback_assignee_id;
+ALTER TABLE feedback DROP COLUMN IF EXISTS assignee_id;
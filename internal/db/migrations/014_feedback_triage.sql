@@ -0,0 +1,30 @@
+-- Triage workflow: an admin assignee per feedback item, internal notes the submitter never
+-- sees, and an append-only audit trail of status changes, assignments and notes.
+ALTER TABLE feedback
+  ADD COLUMN assignee_id UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_feedback_assignee_id ON feedback(assignee_id);
+
+CREATE TABLE feedback_notes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+  author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  body TEXT NOT NULL CHECK (length(trim(body)) > 0 AND char_length(body) <= 4000),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_feedback_notes_feedback_id ON feedback_notes(feedback_id, created_at);
+
+-- from_value / to_value hold statuses for status_changed, user IDs for assigned/unassigned
+-- and the note ID (to_value) for note_added.
+CREATE TABLE feedback_audit (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+  actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  action TEXT NOT NULL CHECK (action IN ('status_changed', 'assigned', 'unassigned', 'note_added')),
+  from_value TEXT,
+  to_value TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_feedback_audit_feedback_id ON feedback_audit(feedback_id, created_at);
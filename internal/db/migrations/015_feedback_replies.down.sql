@@ -0,0 +1,6 @@
+-- Drop feedback replies and their audit action
+DELETE FROM feedback_audit WHERE action = 'reply_added';
+ALTER TABLE feedback_audit DROP CONSTRAINT feedback_audit_action_check;
+ALTER TABLE feedback_audit ADD CONSTRAINT feedback_audit_action_check
+  CHECK (action IN ('status_changed', 'assigned', 'unassigned', 'note_added'));
+DROP TABLE IF EXISTS feedback_replies;
@@ -0,0 +1,15 @@
+-- Public replies from the team to the submitter, shown in the app as a conversation
+-- (oldest first). Replies are also recorded in the audit trail.
+CREATE TABLE feedback_replies (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  feedback_id UUID NOT NULL REFERENCES feedback(id) ON DELETE CASCADE,
+  author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  body TEXT NOT NULL CHECK (length(trim(body)) > 0 AND char_length(body) <= 4000),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_feedback_replies_feedback_id ON feedback_replies(feedback_id, created_at);
+
+ALTER TABLE feedback_audit DROP CONSTRAINT feedback_audit_action_check;
+ALTER TABLE feedback_audit ADD CONSTRAINT feedback_audit_action_check
+  CHECK (action IN ('status_changed', 'assigned', 'unassigned', 'note_added', 'reply_added'));
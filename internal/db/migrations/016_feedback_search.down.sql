@@ -0,0 +1,4 @@
+-- Drop feedback search (pg_trgm stays installed; other database objects may use it)
+DROP INDEX IF EXISTS idx_feedback_message_trgm;
+DROP INDEX IF EXISTS idx_feedback_search_vector;
+ALTER TABLE feedback DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,11 @@
+-- Full-text search over feedback messages for the admin inbox (?q=). search_vector is
+-- kept up to date by Postgres; the trigram index serves the typo-tolerant fallback
+-- (word_similarity, the <% operator). pg_trgm is a trusted extension, so the database
+-- owner can create it without superuser rights.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE feedback
+  ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('english', message)) STORED;
+
+CREATE INDEX idx_feedback_search_vector ON feedback USING GIN (search_vector);
+CREATE INDEX idx_feedback_message_trgm ON feedback USING GIN (message gin_trgm_ops);
@@ -0,0 +1,3 @@
+-- Restore the single-column user index
+CREATE INDEX IF NOT EXISTS idx_feedback_user_id ON feedback(user_id);
+DROP INDEX IF EXISTS idx_feedback_user_id_created_at;
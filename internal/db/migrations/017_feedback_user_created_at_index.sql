@@ -0,0 +1,6 @@
+-- Keyset pagination of a user's feedback (GET /feedback): WHERE user_id = $1 AND
+-- (created_at, id) < (...) ORDER BY created_at DESC, id DESC reads straight off this index
+-- instead of sorting the user's whole history. It also covers user_id lookups, so the
+-- single-column index is dropped.
+CREATE INDEX idx_feedback_user_id_created_at ON feedback(user_id, created_at DESC, id DESC);
+DROP INDEX IF EXISTS idx_feedback_user_id;
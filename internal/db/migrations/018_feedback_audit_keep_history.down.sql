@@ -0,0 +1,8 @@
+-- Restore ON DELETE CASCADE; entries of deleted feedback are dropped
+DELETE FROM feedback_audit WHERE feedback_id IS NULL;
+DROP INDEX IF EXISTS idx_feedback_audit_feedback_ref;
+ALTER TABLE feedback_audit DROP CONSTRAINT feedback_audit_feedback_id_fkey;
+ALTER TABLE feedback_audit ADD CONSTRAINT feedback_audit_feedback_id_fkey
+  FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE CASCADE;
+ALTER TABLE feedback_audit ALTER COLUMN feedback_id SET NOT NULL;
+ALTER TABLE feedback_audit DROP COLUMN IF EXISTS feedback_ref;
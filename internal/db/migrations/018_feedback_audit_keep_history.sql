@@ -0,0 +1,14 @@
+-- Keep the audit trail when feedback is deleted: feedback_ref holds the feedback ID for
+-- good, while feedback_id still references feedback but is set to NULL on delete.
+ALTER TABLE feedback_audit ADD COLUMN feedback_ref UUID;
+UPDATE feedback_audit SET feedback_ref = feedback_id;
+ALTER TABLE feedback_audit ALTER COLUMN feedback_ref SET NOT NULL;
+
+ALTER TABLE feedback_audit ALTER COLUMN feedback_id DROP NOT NULL;
+ALTER TABLE feedback_audit DROP CONSTRAINT feedback_audit_feedback_id_fkey;
+ALTER TABLE feedback_audit ADD CONSTRAINT feedback_audit_feedback_id_fkey
+  FOREIGN KEY (feedback_id) REFERENCES feedback(id) ON DELETE SET NULL;
+
+-- The trail is read by feedback_ref; idx_feedback_audit_feedback_id stays for the SET NULL
+-- lookups when feedback is deleted.
+CREATE INDEX idx_feedback_audit_feedback_ref ON feedback_audit(feedback_ref, created_at);
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Database checks that a connection can be acquired and answers a ping.
+func Database(pool *pgxpool.Pool) Check {
+	return Check{
+		Name: "database",
+		Fn: func(ctx context.Context) error {
+			if err := pool.Ping(ctx); err != nil {
+				return fmt.Errorf("ping failed: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// Tables checks that the given tables exist, i.e. migrations have been applied.
+func Tables(pool *pgxpool.Pool, tables ...string) Check {
+	return Check{
+		Name: "schema",
+		Fn: func(ctx context.Context) error {
+			var missing []string
+			err := pool.QueryRow(ctx, `
+				SELECT coalesce(array_agg(t ORDER BY t), '{}')
+				FROM unnest($1::text[]) AS t
+				WHERE to_regclass(t) IS NULL
+			`, tables).Scan(&missing)
+			if err != nil {
+				return fmt.Errorf("table lookup failed: %w", err)
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"feedback/internal/shared/httpx"
+)
+
+// Check is one readiness dependency. Fn returns nil when the dependency is usable.
+type Check struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a Check in the /readyz response. Failures are logged
+// rather than returned, since /readyz is unauthenticated.
+type CheckResult struct {
+	Status     string `json:"status"` // "ok" or "fail"
+	DurationMS int64  `json:"durationMs"`
+}
+
+// Report is the /readyz response body.
+type Report struct {
+	Status string                 `json:"status"` // "ready", "not_ready" or "shutting_down"
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker serves the liveness and readiness probes.
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+	logger  *slog.Logger
+
+	shuttingDown atomic.Bool
+}
+
+// New creates a checker running checks concurrently, each bounded by timeout
+// (a timeout <= 0 defaults to 2 seconds). Failed checks are logged to logger.
+func New(timeout time.Duration, logger *slog.Logger, checks ...Check) *Checker {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Checker{checks: checks, timeout: timeout, logger: logger}
+}
+
+// SetShuttingDown makes /readyz fail from now on so load balancers stop routing
+// new traffic here while in-flight requests finish.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Live handles GET /livez: the process is up and serving HTTP. It checks no dependencies,
+// so a database outage never gets the instance restarted.
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	httpx.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Ready handles GET /readyz: 200 when every check passes, 503 otherwise or while shutting down.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	httpx.WriteJSON(w, status, report)
+}
+
+// Run executes every check and builds the report.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Fn(ctx)
+			results[i] = CheckResult{Status: "ok", DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				results[i].Status = "fail"
+				c.logger.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: "ready", Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, check := range c.checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "not_ready"
+		}
+	}
+	if c.shuttingDown.Load() {
+		report.Status = "shutting_down"
+	}
+	return report
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// New returns a logger writing to w in format ("json" or "text") at level
+// ("debug", "info", "warn" or "error"). Every record logged with a request
+// context carries that request's request_id and, once authenticated, user_id.
+func New(w io.Writer, format, level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level)}
+
+	var h slog.Handler
+	if format == "text" {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{h})
+}
+
+// ParseLevel maps a LOG_LEVEL value to a slog level, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type requestKey struct{}
+
+// requestInfo is shared by every context derived from the request, so a user ID
+// set deep in the handler chain is also seen by outer middleware (the access log).
+type requestInfo struct {
+	requestID string
+	userID    atomic.Pointer[string]
+}
+
+// WithRequestID returns a context that tags log records with requestID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestKey{}, &requestInfo{requestID: requestID})
+}
+
+// RequestID returns the request ID stored by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		return info.requestID
+	}
+	return ""
+}
+
+// SetUserID tags the rest of the request's log records with the authenticated user.
+// It does nothing outside a WithRequestID context.
+func SetUserID(ctx context.Context, userID string) {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		info.userID.Store(&userID)
+	}
+}
+
+// contextHandler adds the request attributes found in the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if info, ok := ctx.Value(requestKey{}).(*requestInfo); ok {
+		r.AddAttrs(slog.String("request_id", info.requestID))
+		if userID := info.userID.Load(); userID != nil {
+			r.AddAttrs(slog.String("user_id", *userID))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// FileMailer writes each message as an .eml file for local development.
+// Open the files with any mail client to check rendering and links.
+type FileMailer struct {
+	dir    string
+	from   string
+	logger *slog.Logger
+}
+
+func NewFileMailer(dir, from string, logger *slog.Logger) (*FileMailer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail file dir is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail dir: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from, logger: logger}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	body, err := buildMIME(m.from, msg, now)
+	if err != nil {
+		return fmt.Errorf("mail build failed: %w", err)
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", now.UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+
+	m.logger.InfoContext(ctx, "mail written to file", "to", msg.To, "subject", msg.Subject, "path", path)
+	return nil
+}
@@ -0,0 +1,37 @@
+package mail
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+)
+
+// unsafeLinkSchemes can run code or smuggle content when clicked; Link refuses them.
+var unsafeLinkSchemes = map[string]bool{"javascript": true, "vbscript": true, "data": true, "file": true}
+
+// Link returns base (e.g. APP_DEEPLINK_URL) with params added to its query string. Query
+// parameters already in base are kept unless params sets the same key; a trailing slash
+// on the path is dropped.
+//
+// The result is a template.URL so html/template keeps custom app schemes such as
+// feedbackapp:// in href attributes (it would otherwise replace them with #ZgotmplZ).
+// That is only safe because base must be an absolute URL with a scheme not in unsafeLinkSchemes.
+func Link(base string, params url.Values) (htmltemplate.URL, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid link base URL: %w", err)
+	}
+	if u.Scheme == "" || unsafeLinkSchemes[strings.ToLower(u.Scheme)] {
+		return "", fmt.Errorf("link base URL must be absolute with an http(s) or app scheme, got %q", base)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return htmltemplate.URL(u.String()), nil
+}
@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogMailer prints messages to the log instead of sending them. Bodies carry login links
+// and codes, so they are only logged at debug level; only use it in development.
+type LogMailer struct {
+	from   string
+	logger *slog.Logger
+}
+
+func NewLogMailer(from string, logger *slog.Logger) *LogMailer {
+	return &LogMailer{from: from, logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.logger.InfoContext(ctx, "mail not sent (log backend)", "from", m.from, "to", msg.To, "subject", msg.Subject)
+
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	m.logger.DebugContext(ctx, "mail body (log backend)", "to", msg.To, "body", body)
+	return nil
+}
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"feedback/internal/metrics"
+)
+
+// Message is a single email with a plain-text body and an optional HTML alternative.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends email. Implementations are selected by Config.Backend.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Mail backends accepted in Config.Backend (MAIL_BACKEND).
+const (
+	BackendMailgun = "mailgun"
+	BackendSMTP    = "smtp"
+	BackendFile    = "file"
+	BackendLog     = "log"
+)
+
+// Config selects and configures the Mailer returned by New.
+type Config struct {
+	Backend string // MAIL_BACKEND: mailgun, smtp, file or log
+	From    string // EMAIL_FROM, e.g. "FeedbackApp <postmaster@sandboxxxxx.mailgun.org>"
+
+	Mailgun MailgunConfig
+	SMTP    SMTPConfig
+
+	Dir string // MAIL_FILE_DIR, where the file backend writes .eml files
+
+	Logger *slog.Logger // used by the file and log backends
+}
+
+// New returns the Mailer selected by cfg.Backend. Sends are counted in metrics.EmailSends.
+func New(cfg Config) (Mailer, error) {
+	var m Mailer
+	var err error
+	switch cfg.Backend {
+	case BackendMailgun:
+		m, err = NewMailgunMailer(cfg.Mailgun, cfg.From)
+	case BackendSMTP:
+		m, err = NewSMTPMailer(cfg.SMTP, cfg.From)
+	case BackendFile:
+		m, err = NewFileMailer(cfg.Dir, cfg.From, cfg.Logger)
+	case BackendLog:
+		m = NewLogMailer(cfg.From, cfg.Logger)
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", cfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &countingMailer{Mailer: m, backend: cfg.Backend}, nil
+}
+
+// countingMailer records the outcome of every send.
+type countingMailer struct {
+	Mailer
+	backend string
+}
+
+func (m *countingMailer) Send(ctx context.Context, msg Message) error {
+	err := m.Mailer.Send(ctx, msg)
+	outcome := metrics.OutcomeSuccess
+	if err != nil {
+		outcome = metrics.OutcomeFailure
+	}
+	metrics.EmailSends.Inc(m.backend, outcome)
+	return err
+}
+
+// validate checks the fields every backend needs.
+func (m Message) validate() error {
+	if m.To == "" {
+		return fmt.Errorf("to is required")
+	}
+	if m.Subject == "" {
+		return fmt.Errorf("subject is required")
+	}
+	if m.Text == "" && m.HTML == "" {
+		return fmt.Errorf("text or html body is required")
+	}
+	return nil
+}
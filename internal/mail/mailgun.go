@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MailgunConfig configures the Mailgun HTTP API backend.
+type MailgunConfig struct {
+	APIKey  string // MAILGUN_API_KEY
+	Domain  string // MAILGUN_DOMAIN, e.g. sandboxxxxx.mailgun.org
+	BaseURL string // MAILGUN_BASE_URL, e.g. https://api.mailgun.net (or EU base)
+}
+
+// MailgunMailer sends through the Mailgun messages API.
+type MailgunMailer struct {
+	cfg        MailgunConfig
+	from       string
+	httpClient *http.Client
+}
+
+func NewMailgunMailer(cfg MailgunConfig, from string) (*MailgunMailer, error) {
+	if cfg.APIKey == "" || cfg.Domain == "" || cfg.BaseURL == "" || from == "" {
+		return nil, fmt.Errorf("mailgun config missing")
+	}
+	return &MailgunMailer{
+		cfg:        cfg,
+		from:       from,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (m *MailgunMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("from", m.from)
+	form.Set("to", msg.To)
+	form.Set("subject", msg.Subject)
+	if msg.Text != "" {
+		form.Set("text", msg.Text)
+	}
+	if msg.HTML != "" {
+		form.Set("html", msg.HTML)
+	}
+
+	endpoint := strings.TrimRight(m.cfg.BaseURL, "/") + "/v3/" + m.cfg.Domain + "/messages"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("mailgun request build failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Basic auth: username "api", password API key
+	req.SetBasicAuth("api", m.cfg.APIKey)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("mailgun send failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("mailgun send failed: status=%d body=%s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
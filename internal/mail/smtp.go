@@ -0,0 +1,118 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig configures the SMTP backend (submission with required STARTTLS).
+type SMTPConfig struct {
+	Host     string // SMTP_HOST
+	Port     int    // SMTP_PORT, usually 587
+	Username string // SMTP_USERNAME, empty for unauthenticated relays
+	Password string // SMTP_PASSWORD
+
+	// AllowInsecure (SMTP_ALLOW_INSECURE) sends in plaintext when the server does not offer
+	// STARTTLS. config.Load only accepts it for a loopback Host.
+	AllowInsecure bool
+}
+
+// SMTPMailer sends through an SMTP server. STARTTLS is required unless AllowInsecure is set,
+// and credentials are never sent without it.
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from string
+}
+
+func NewSMTPMailer(cfg SMTPConfig, from string) (*SMTPMailer, error) {
+	if cfg.Host == "" || from == "" {
+		return nil, fmt.Errorf("smtp config missing")
+	}
+	if _, err := netmail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	body, err := buildMIME(m.from, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("smtp message build failed: %w", err)
+	}
+
+	fromAddr, _ := netmail.ParseAddress(m.from)
+	toAddr, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("smtp dial failed: %w", err)
+	}
+	// net/smtp has no context support; bound the whole exchange with a deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp handshake failed: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	} else if !m.cfg.AllowInsecure {
+		return fmt.Errorf("smtp server %s does not support STARTTLS", addr)
+	} else if m.cfg.Username != "" {
+		return fmt.Errorf("smtp server %s does not support STARTTLS; refusing to send credentials", addr)
+	}
+
+	if m.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(fromAddr.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %w", err)
+	}
+	if err := c.Rcpt(toAddr.Address); err != nil {
+		return fmt.Errorf("smtp RCPT TO failed: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("smtp write failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,196 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Branding is injected into every email template as .Brand.
+type Branding struct {
+	AppName      string // BRAND_APP_NAME, e.g. FeedbackApp
+	PrimaryColor string // BRAND_PRIMARY_COLOR, hex color for buttons and headings
+	TextColor    string // BRAND_TEXT_COLOR, hex color for body text
+	SupportEmail string // BRAND_SUPPORT_EMAIL, shown in the footer when set
+}
+
+// TemplateData is the root value templates are executed with.
+type TemplateData struct {
+	Brand   Branding
+	Locale  string
+	Subject string // rendered subject, available to the HTML layout
+	Data    any    // email-specific values
+}
+
+// Templates renders the embedded emails. Each email is a directory under templates/
+// with one <locale>.txt.tmpl (defining "subject" and "body") and one <locale>.html.tmpl
+// (defining "content", wrapped by layout.html.tmpl) per locale.
+type Templates struct {
+	brand         Branding
+	defaultLocale string
+
+	text map[string]*texttemplate.Template // key: name/locale
+	html map[string]*htmltemplate.Template
+	// locales lists the supported locales of each email.
+	locales map[string][]string
+}
+
+// NewTemplates parses the embedded templates. defaultLocale is used when none of the
+// requested locales is available and must exist for every email.
+func NewTemplates(brand Branding, defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		brand:         brand,
+		defaultLocale: defaultLocale,
+		text:          map[string]*texttemplate.Template{},
+		html:          map[string]*htmltemplate.Template{},
+		locales:       map[string][]string{},
+	}
+
+	dirs, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email templates: %w", err)
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		name := dir.Name()
+
+		files, err := fs.Glob(templatesFS, path.Join("templates", name, "*.txt.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s templates: %w", name, err)
+		}
+
+		for _, file := range files {
+			locale := strings.TrimSuffix(path.Base(file), ".txt.tmpl")
+			key := name + "/" + locale
+
+			text, err := texttemplate.ParseFS(templatesFS, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+			html, err := htmltemplate.ParseFS(templatesFS,
+				"templates/layout.html.tmpl", path.Join("templates", name, locale+".html.tmpl"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s html template: %w", key, err)
+			}
+
+			t.text[key] = text
+			t.html[key] = html
+			t.locales[name] = append(t.locales[name], locale)
+		}
+
+		if _, ok := t.text[name+"/"+defaultLocale]; !ok {
+			return nil, fmt.Errorf("email %q has no %q template", name, defaultLocale)
+		}
+		sort.Strings(t.locales[name])
+	}
+
+	return t, nil
+}
+
+// Locales returns the locales available for the named email.
+func (t *Templates) Locales(name string) []string {
+	return t.locales[name]
+}
+
+// Render builds the named email for the first supported locale in preferred
+// (e.g. an explicit locale followed by ParseAcceptLanguage), falling back to the default locale.
+func (t *Templates) Render(name string, preferred []string, to string, data any) (Message, error) {
+	if _, ok := t.locales[name]; !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	locale := t.matchLocale(name, preferred)
+	key := name + "/" + locale
+	td := TemplateData{Brand: t.brand, Locale: locale, Data: data}
+
+	var subject, text, html bytes.Buffer
+	if err := t.text[key].ExecuteTemplate(&subject, "subject", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %w", key, err)
+	}
+	td.Subject = strings.TrimSpace(subject.String())
+
+	if err := t.text[key].ExecuteTemplate(&text, "body", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s text: %w", key, err)
+	}
+	if err := t.html[key].ExecuteTemplate(&html, "layout", td); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s html: %w", key, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: td.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// matchLocale picks the first preferred locale the email supports, comparing exact
+// tags first and then the base language ("es-MX" matches "es").
+func (t *Templates) matchLocale(name string, preferred []string) string {
+	supported := t.locales[name]
+	for _, want := range preferred {
+		want = strings.ToLower(strings.TrimSpace(want))
+		if want == "" {
+			continue
+		}
+		base, _, _ := strings.Cut(want, "-")
+		for _, have := range supported {
+			if have == want || have == base {
+				return have
+			}
+		}
+	}
+	return t.defaultLocale
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header,
+// highest quality first. Tags with q=0 and the "*" wildcard are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type tag struct {
+		value string
+		q     float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		value, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		value = strings.TrimSpace(value)
+		if value == "" || value == "*" {
+			continue
+		}
+
+		q := 1.0
+		if qs, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(qs, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, tag{value: value, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	values := make([]string, len(tags))
+	for i, t := range tags {
+		values[i] = t.value
+	}
+	return values
+}
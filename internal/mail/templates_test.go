@@ -0,0 +1,81 @@
+package mail
+
+import (
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func testTemplates(t *testing.T) *Templates {
+	t.Helper()
+	templates, err := NewTemplates(Branding{
+		AppName:      "FeedbackApp",
+		PrimaryColor: "#4f46e5",
+		TextColor:    "#111111",
+		SupportEmail: "support@example.com",
+	}, "en")
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	return templates
+}
+
+// renderLinkEmail renders the named email in every locale with a link built from each
+// base and checks the link reaches both parts intact.
+func renderLinkEmail(t *testing.T, name string, params url.Values, data func(link htmltemplate.URL) any) {
+	templates := testTemplates(t)
+
+	for _, base := range []string{"feedbackapp://auth", "https://api.example.com/auth/deeplink"} {
+		link, err := Link(base, params)
+		if err != nil {
+			t.Fatalf("Link(%q): %v", base, err)
+		}
+		for _, locale := range templates.Locales(name) {
+			t.Run(locale+" "+base, func(t *testing.T) {
+				msg, err := templates.Render(name, []string{locale}, "user@example.com", data(link))
+				if err != nil {
+					t.Fatalf("Render: %v", err)
+				}
+				if want := `href="` + string(link) + `"`; !strings.Contains(msg.HTML, want) {
+					t.Errorf("HTML has no %s", want)
+				}
+				if strings.Contains(msg.HTML, "ZgotmplZ") {
+					t.Errorf("html/template rejected the link:\n%s", msg.HTML)
+				}
+				if !strings.Contains(msg.Text, string(link)) {
+					t.Errorf("text part has no %s", link)
+				}
+			})
+		}
+	}
+}
+
+func TestLoginLinkHref(t *testing.T) {
+	renderLinkEmail(t, "login_link", url.Values{"token": {"tok123"}}, func(link htmltemplate.URL) any {
+		return struct {
+			Link             htmltemplate.URL
+			Code             string
+			ExpiresInMinutes int
+		}{link, "123456", 15}
+	})
+}
+
+func TestLinkRejectsUnsafeBase(t *testing.T) {
+	for _, base := range []string{"javascript:alert(1)", "data:text/html,hi", "/auth/deeplink", "auth/deeplink"} {
+		if link, err := Link(base, url.Values{"token": {"x"}}); err == nil {
+			t.Errorf("Link(%q) = %q, want an error", base, link)
+		}
+	}
+}
+
+func TestFeedbackReplyHref(t *testing.T) {
+	params := url.Values{"feedback_id": {"660e8400-e29b-41d4-a716-446655440000"}}
+	renderLinkEmail(t, "feedback_reply", params, func(link htmltemplate.URL) any {
+		return struct {
+			Reply    string
+			Feedback string
+			Link     htmltemplate.URL
+		}{"Thanks, dark mode ships next week.", "Dark mode please", link}
+	})
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Outcome label values shared by the application counters.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeRetry   = "retry"
+	OutcomeDead    = "dead"
+)
+
+var (
+	// EmailSends counts Mailer.Send calls by backend and outcome (success, failure).
+	EmailSends = Default.NewCounterVec("feedback_email_sends_total",
+		"Emails handed to the mail backend, by backend and outcome.", "backend", "outcome")
+
+	// SlackPublishes counts outbox deliveries by outcome: success, retry (failed, will be
+	// retried) or dead (failed for the last time).
+	SlackPublishes = Default.NewCounterVec("feedback_slack_publishes_total",
+		"Slack outbox deliveries, by outcome.", "outcome")
+)
+
+// RegisterPool exposes pgxpool.Stat() as gauges and counters read on every scrape.
+func RegisterPool(pool *pgxpool.Pool) {
+	stat := func(fn func(s *pgxpool.Stat) float64) func() float64 {
+		return func() float64 { return fn(pool.Stat()) }
+	}
+
+	Default.NewGaugeFunc("db_pool_acquired_conns", "Connections currently checked out of the pool.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }))
+	Default.NewGaugeFunc("db_pool_idle_conns", "Idle connections in the pool.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }))
+	Default.NewGaugeFunc("db_pool_total_conns", "Open connections (acquired, idle and constructing).",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }))
+	Default.NewGaugeFunc("db_pool_max_conns", "Maximum size of the pool.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }))
+	Default.NewCounterFunc("db_pool_acquires_total", "Successful connection acquisitions.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }))
+	Default.NewCounterFunc("db_pool_empty_acquires_total", "Acquisitions that had to wait because the pool was empty.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }))
+	Default.NewCounterFunc("db_pool_canceled_acquires_total", "Acquisitions canceled by their context.",
+		stat(func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }))
+	Default.NewCounterFunc("db_pool_acquire_seconds_total", "Total time spent waiting to acquire connections.",
+		stat(func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }))
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	httpRequestDuration = Default.NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method, route pattern and status code.", DefBuckets, "method", "route", "status")
+
+	httpRequestsInFlight atomic.Int64
+)
+
+func init() {
+	Default.NewGaugeFunc("http_requests_in_flight", "HTTP requests currently being served.",
+		func() float64 { return float64(httpRequestsInFlight.Load()) })
+}
+
+// Middleware records a latency histogram per route. It must wrap the ServeMux directly:
+// the route label is the pattern the mux matched (r.Pattern, e.g. "/feedback/{id}"),
+// which keeps the label set small, and "unmatched" for requests no route handled.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		httpRequestsInFlight.Add(1)
+		defer httpRequestsInFlight.Add(-1)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		httpRequestDuration.Observe(time.Since(start).Seconds(),
+			methodLabel(r.Method), routeLabel(r.Pattern), strconv.Itoa(rec.status))
+	})
+}
+
+// routeLabel strips the method from a Go 1.22 pattern ("GET /feedback" → "/feedback").
+func routeLabel(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+// methodLabel folds non-standard methods into "other" so clients cannot grow the label set.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	default:
+		return "other"
+	}
+}
+
+// Handler serves the Default registry. With a non-empty token, requests must send
+// "Authorization: Bearer <token>".
+func Handler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Default.WriteText(w)
+	})
+}
+
+// statusRecorder captures the status code written by the handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"feedback/internal/logging"
 	"feedback/internal/shared/httpx"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,30 +18,54 @@ type contextKey string
 const (
 	userIDKey    contextKey = "userID"
 	userEmailKey contextKey = "userEmail"
+	userRoleKey  contextKey = "userRole"
+	sessionIDKey contextKey = "sessionID"
 )
 
+// User roles carried in the JWT role claim (users.role).
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Errors written by RequireAuth.
+var (
+	errMissingAuthorization = httpx.NewError(http.StatusUnauthorized, "missing_authorization", "The Authorization header is required.")
+	errInvalidAuthFormat    = httpx.NewError(http.StatusUnauthorized, "invalid_authorization_format", "The Authorization header must be \"Bearer <token>\".")
+	errInvalidToken         = httpx.NewError(http.StatusUnauthorized, "invalid_token", "The access token is invalid or expired.")
+	errInvalidClaims        = httpx.NewError(http.StatusUnauthorized, "invalid_claims", "The access token claims are invalid.")
+	errSessionRevoked       = httpx.NewError(http.StatusUnauthorized, "session_revoked", "This session has been logged out.")
+)
+
+// errInsufficientRole is written by RequireRole.
+var errInsufficientRole = httpx.NewError(http.StatusForbidden, "insufficient_role", "You do not have permission to use this endpoint.")
+
 // JWTClaims matches the structure from auth.jwt.go
 type JWTClaims struct {
-	Email string `json:"email"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 // RequireAuth is a middleware that validates JWT tokens and extracts user identity.
 // It expects the Authorization header in the format: "Bearer <token>"
-func RequireAuth(jwtSecret string) func(http.HandlerFunc) http.HandlerFunc {
+// Tokens whose session has been revoked (logout) are rejected; sessions is usually a *SessionCache.
+// The user ID is added to the request's log records once the token is accepted.
+func RequireAuth(jwtSecret string, sessions SessionChecker, logger *slog.Logger) func(http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			// Extract Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				httpx.WriteError(w, http.StatusUnauthorized, "missing_authorization")
+				httpx.WriteErr(w, errMissingAuthorization)
 				return
 			}
 
 			// Check Bearer prefix
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				httpx.WriteError(w, http.StatusUnauthorized, "invalid_authorization_format")
+				httpx.WriteErr(w, errInvalidAuthFormat)
 				return
 			}
 
@@ -55,20 +81,42 @@ func RequireAuth(jwtSecret string) func(http.HandlerFunc) http.HandlerFunc {
 			})
 
 			if err != nil || !token.Valid {
-				httpx.WriteError(w, http.StatusUnauthorized, "invalid_token")
+				httpx.WriteErr(w, errInvalidToken)
 				return
 			}
 
 			// Extract claims
 			claims, ok := token.Claims.(*JWTClaims)
 			if !ok {
-				httpx.WriteError(w, http.StatusUnauthorized, "invalid_claims")
+				httpx.WriteErr(w, errInvalidClaims)
+				return
+			}
+
+			// Tokens issued before sessions existed carry no sid and cannot be revoked
+			if claims.SessionID == "" {
+				httpx.WriteErr(w, errInvalidToken)
+				return
+			}
+
+			// Check the session has not been logged out
+			revoked, err := sessions.IsSessionRevoked(r.Context(), claims.SessionID)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "session check failed", "session_id", claims.SessionID, "error", err)
+				httpx.WriteErr(w, err)
+				return
+			}
+			if revoked {
+				httpx.WriteErr(w, errSessionRevoked)
 				return
 			}
 
+			logging.SetUserID(r.Context(), claims.Subject)
+
 			// Store user info in context
 			ctx := context.WithValue(r.Context(), userIDKey, claims.Subject)
 			ctx = context.WithValue(ctx, userEmailKey, claims.Email)
+			ctx = context.WithValue(ctx, userRoleKey, claims.Role)
+			ctx = context.WithValue(ctx, sessionIDKey, claims.SessionID)
 
 			// Call next handler with updated context
 			next(w, r.WithContext(ctx))
@@ -76,6 +124,21 @@ func RequireAuth(jwtSecret string) func(http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// RequireRole rejects requests whose token does not carry role with 403 insufficient_role.
+// It must run inside RequireAuth, e.g. requireAuth(middleware.RequireRole(middleware.RoleAdmin)(h)).
+// Tokens issued before roles existed carry none and are treated as RoleUser.
+func RequireRole(role string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if userRole, _ := GetRole(r); userRole != role {
+				httpx.WriteErr(w, errInsufficientRole.WithDetails(map[string]string{"requiredRole": role}))
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
 // GetAuthUser extracts the authenticated user's ID and email from the request context.
 // Returns (userID, email, true) if found, or ("", "", false) if not found.
 func GetAuthUser(r *http.Request) (userID string, email string, ok bool) {
@@ -83,3 +146,18 @@ func GetAuthUser(r *http.Request) (userID string, email string, ok bool) {
 	email, ok2 := r.Context().Value(userEmailKey).(string)
 	return userID, email, ok1 && ok2
 }
+
+// GetSessionID returns the session ID (sid claim) of the authenticated request.
+func GetSessionID(r *http.Request) (string, bool) {
+	sessionID, ok := r.Context().Value(sessionIDKey).(string)
+	return sessionID, ok
+}
+
+// GetRole returns the role claim of the authenticated request, RoleUser when the token has none.
+func GetRole(r *http.Request) (string, bool) {
+	role, ok := r.Context().Value(userRoleKey).(string)
+	if ok && role == "" {
+		role = RoleUser
+	}
+	return role, ok
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SessionChecker reports whether a session (the JWT sid claim) has been revoked.
+type SessionChecker interface {
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// SessionCache wraps a SessionChecker with an in-memory cache so RequireAuth does not
+// hit Postgres on every request. A revocation made on another instance is picked up
+// once the cached entry expires, i.e. within ttl.
+type SessionCache struct {
+	checker SessionChecker
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]sessionCacheEntry
+	lastSweep time.Time
+}
+
+type sessionCacheEntry struct {
+	revoked   bool
+	expiresAt time.Time
+}
+
+// NewSessionCache creates a cache in front of checker. A ttl <= 0 defaults to 30 seconds.
+func NewSessionCache(checker SessionChecker, ttl time.Duration) *SessionCache {
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &SessionCache{
+		checker:   checker,
+		ttl:       ttl,
+		entries:   map[string]sessionCacheEntry{},
+		lastSweep: time.Now(),
+	}
+}
+
+// IsSessionRevoked returns the cached state of the session, asking the checker on a miss.
+func (c *SessionCache) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[sessionID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.checker.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	c.sweep(now)
+	c.entries[sessionID] = sessionCacheEntry{revoked: revoked, expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return revoked, nil
+}
+
+// Revoke marks sessions as revoked immediately on this instance (e.g. after logout).
+func (c *SessionCache) Revoke(sessionIDs ...string) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range sessionIDs {
+		c.entries[id] = sessionCacheEntry{revoked: true, expiresAt: now.Add(c.ttl)}
+	}
+}
+
+// sweep drops expired entries at most once per ttl. Callers must hold c.mu.
+func (c *SessionCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	c.lastSweep = now
+}
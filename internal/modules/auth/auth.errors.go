@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"net/http"
+
+	"feedback/internal/shared/httpx"
+)
+
+// API errors returned by the auth module; handlers write them with httpx.WriteErr.
+var (
+	errUnauthorized          = httpx.NewError(http.StatusUnauthorized, "unauthorized", "Authentication is required.")
+	errInvalidUserID         = httpx.NewError(http.StatusUnauthorized, "invalid_user_id", "The access token does not identify a valid user.")
+	errInvalidToken          = httpx.NewError(http.StatusUnauthorized, "invalid_token", "The access token is invalid or expired.")
+	errEmailRequired         = httpx.NewError(http.StatusBadRequest, "email_required", "Email is required.")
+	errInvalidCodeChallenge  = httpx.NewError(http.StatusBadRequest, "invalid_code_challenge", "codeChallenge must be a base64url SHA-256 PKCE challenge.")
+	errInvalidCodeVerifier   = httpx.NewError(http.StatusBadRequest, "invalid_code_verifier", "codeVerifier must be 43-128 characters of [A-Za-z0-9-._~].")
+	errRateLimited           = httpx.NewError(http.StatusTooManyRequests, "rate_limited", "Too many login requests. Please wait before trying again.")
+	errEmailSendFailed       = httpx.NewError(http.StatusInternalServerError, "email_send_failed", "We could not send the login email. Please try again.")
+	errInvalidOrExpiredToken = httpx.NewError(http.StatusUnauthorized, "invalid_or_expired_token", "This login link is invalid, expired or was already used.")
+	errInvalidLoginCode      = httpx.NewError(http.StatusUnauthorized, "invalid_or_expired_code", "This login code is invalid or expired.")
+	errLoginCodeLocked       = httpx.NewError(http.StatusTooManyRequests, "too_many_attempts", "Too many wrong codes. Use the login link or request a new one.")
+	errInvalidRefreshToken   = httpx.NewError(http.StatusUnauthorized, "invalid_refresh_token", "The refresh token is invalid or expired.")
+	errRefreshTokenReused    = httpx.NewError(http.StatusUnauthorized, "refresh_token_reused", "This refresh token was already used; the session has been revoked.")
+)
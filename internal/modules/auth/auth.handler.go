@@ -1,41 +1,62 @@
 package auth
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"strings"
+	"strconv"
 
+	"feedback/internal/mail"
+	"feedback/internal/middleware"
 	"feedback/internal/shared/httpx"
+
+	"github.com/google/uuid"
 )
 
 type Handler struct {
 	service *Service
+	logger  *slog.Logger
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *Service, logger *slog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
+}
+
+// writeErr writes err as an API error, logging it first when it is unexpected (5xx).
+func (h *Handler) writeErr(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if httpx.IsServerError(err) {
+		h.logger.ErrorContext(r.Context(), msg, "error", err)
+	}
+	httpx.WriteErr(w, err)
 }
 
 func (h *Handler) HandleRequestLoginLink(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
 		return
 	}
 
 	var req RequestLoginLinkRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.WriteError(w, http.StatusBadRequest, "invalid_json")
+	if err := httpx.DecodeJSON(w, r, &req, 0); err != nil {
+		httpx.WriteErr(w, err)
 		return
 	}
 
-	if err := h.service.RequestLoginLink(r.Context(), req.Email); err != nil {
-		if strings.Contains(err.Error(), "email_send_failed") {
-			httpx.WriteError(w, http.StatusInternalServerError, "email_send_failed")
-			return
+	// An explicit locale wins over the Accept-Language header
+	locales := mail.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if req.Locale != "" {
+		locales = append([]string{req.Locale}, locales...)
+	}
+
+	clientIP := httpx.ClientIP(r, h.service.rateLimits.TrustProxyHeaders)
+	if err := h.service.RequestLoginLink(r.Context(), req, clientIP, locales); err != nil {
+		var rateLimited *rateLimitedError
+		if errors.As(err, &rateLimited) {
+			w.Header().Set("Retry-After", strconv.Itoa(rateLimited.retryAfterSeconds()))
 		}
-		httpx.WriteError(w, http.StatusInternalServerError, "internal_error")
+		h.writeErr(w, r, "request login link failed", err)
 		return
 	}
 
@@ -45,48 +66,165 @@ func (h *Handler) HandleRequestLoginLink(w http.ResponseWriter, r *http.Request)
 // HandleVerifyLoginLink handles POST /auth/login-link/verify
 func (h *Handler) HandleVerifyLoginLink(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
 		return
 	}
 
 	var req VerifyLoginLinkRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.WriteError(w, http.StatusBadRequest, "invalid_json")
+	if err := httpx.DecodeJSON(w, r, &req, 0); err != nil {
+		httpx.WriteErr(w, err)
 		return
 	}
 
-	resp, err := h.service.VerifyLoginLink(r.Context(), req.Token)
+	resp, err := h.service.VerifyLoginLink(r.Context(), req.Token, req.CodeVerifier)
 	if err != nil {
-		if strings.Contains(err.Error(), "invalid_or_expired_token") {
-			httpx.WriteError(w, http.StatusUnauthorized, "invalid_or_expired_token")
-			return
-		}
-		httpx.WriteError(w, http.StatusInternalServerError, "internal_error")
+		h.writeErr(w, r, "verify login link failed", err)
 		return
 	}
 
 	httpx.WriteJSON(w, http.StatusOK, resp)
 }
 
+// HandleVerifyLoginCode handles POST /auth/login-code/verify
+func (h *Handler) HandleVerifyLoginCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
+		return
+	}
+
+	var req VerifyLoginCodeRequest
+	if err := httpx.DecodeJSON(w, r, &req, 0); err != nil {
+		httpx.WriteErr(w, err)
+		return
+	}
+
+	resp, err := h.service.VerifyLoginCode(r.Context(), req.Email, req.Code, req.CodeVerifier)
+	if err != nil {
+		h.writeErr(w, r, "verify login code failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleRefreshTokens handles POST /auth/refresh
+func (h *Handler) HandleRefreshTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
+		return
+	}
+
+	var req RefreshTokenRequest
+	if err := httpx.DecodeJSON(w, r, &req, 0); err != nil {
+		httpx.WriteErr(w, err)
+		return
+	}
+
+	resp, err := h.service.RefreshTokens(r.Context(), req.RefreshToken)
+	if err != nil {
+		h.writeErr(w, r, "refresh tokens failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleLogout handles POST /auth/logout (revokes the current session)
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authUserID(w, r)
+	if !ok {
+		return
+	}
+	sid, _ := middleware.GetSessionID(r)
+	sessionID, err := uuid.Parse(sid)
+	if err != nil {
+		httpx.WriteErr(w, errInvalidToken)
+		return
+	}
+
+	if err := h.service.Logout(r.Context(), userID, sessionID); err != nil {
+		h.writeErr(w, r, "logout failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, LogoutResponse{OK: true})
+}
+
+// HandleLogoutAll handles POST /auth/logout-all (revokes every session of the user)
+func (h *Handler) HandleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
+		return
+	}
+
+	userID, ok := authUserID(w, r)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.service.LogoutAll(r.Context(), userID)
+	if err != nil {
+		h.writeErr(w, r, "logout all failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, LogoutAllResponse{OK: true, SessionsRevoked: revoked})
+}
+
+// authUserID extracts the authenticated user ID (set by middleware) and writes a 401 if missing.
+func authUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr, _, ok := middleware.GetAuthUser(r)
+	if !ok {
+		httpx.WriteErr(w, errUnauthorized)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		httpx.WriteErr(w, errInvalidUserID)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
 func (h *Handler) HandleDeeplink(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed")
+		httpx.WriteErr(w, httpx.ErrMethodNotAllowed)
 		return
 	}
 
-	rawToken := r.URL.Query().Get("token")
-	if rawToken == "" {
+	query := r.URL.Query()
+
+	// Reply emails link here with ?feedback_id= to open the feedback in the app; login links carry ?token=
+	var target, note string
+	if rawToken := query.Get("token"); rawToken != "" {
+		target = "feedbackapp://auth?token=" + url.QueryEscape(rawToken)
+		note = fmt.Sprintf("This login link expires in %d minutes.", int(loginLinkTTL.Minutes()))
+	} else if id, err := uuid.Parse(query.Get("feedback_id")); err == nil {
+		target = "feedbackapp://feedback?id=" + id.String()
+	} else {
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(http.StatusBadRequest)
 		_, _ = w.Write([]byte("missing token"))
 		return
 	}
 
-	target := "feedbackapp://auth?token=" + url.QueryEscape(rawToken)
-
+	// The page never consumes the link (only the requesting app, holding the code verifier,
+	// can), so scanners prefetching it are harmless. Keep the token out of caches and referrers.
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
 	w.WriteHeader(http.StatusOK)
 
+	if note != "" {
+		note = `<p style="color:#666;margin-top:24px;">` + note + `</p>`
+	}
+
 	// Small HTML that:
 	// - tries to open immediately
 	// - provides a button fallback
@@ -106,11 +244,11 @@ func (h *Handler) HandleDeeplink(w http.ResponseWriter, r *http.Request) {
       Open FeedbackApp
     </a>
   </p>
-  <p style="color:#666;margin-top:24px;">This login link expires in 15 minutes.</p>
+  %s
   <script>
     // Try to open immediately (some clients require a user gesture; button remains as fallback).
     window.location.href = %q;
   </script>
 </body>
-</html>`, target, target)
+</html>`, target, note, target)
 }
@@ -5,23 +5,31 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JWTClaims struct {
-	Email string `json:"email"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// CreateJWT generates a JWT token with HS256 signing.
-// Token expires in 30 days.
-func CreateJWT(userID, email, secret string) (string, error) {
+// CreateJWT generates a short-lived access token with HS256 signing.
+// Token expires after ttl; clients renew it with a refresh token.
+// sid ties the token to its session so logout can revoke it; jti is unique per token.
+// role is read by middleware.RequireRole; a changed role takes effect at the next refresh.
+func CreateJWT(userID, email, role, sessionID, secret string, ttl time.Duration) (string, error) {
 	now := time.Now()
 	claims := JWTClaims{
-		Email: email,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(10 * 365 * 24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 		},
 	}
 
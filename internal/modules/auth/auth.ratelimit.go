@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RateLimit is a token bucket: Burst requests at once, refilled at Burst per Per.
+// A zero Burst disables the limit.
+type RateLimit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// refillPerSecond is the number of tokens added back each second.
+func (l RateLimit) refillPerSecond() float64 {
+	return float64(l.Burst) / l.Per.Seconds()
+}
+
+// RateLimitStore keeps token buckets keyed by e.g. "login-link:email:<address>".
+// Take consumes one token and, when none is left, returns false and the wait until the next one.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+}
+
+// RateLimitConfig configures the limits on POST /auth/login-link.
+type RateLimitConfig struct {
+	PerEmail RateLimit      // LOGIN_LINK_EMAIL_LIMIT / LOGIN_LINK_EMAIL_WINDOW
+	PerIP    RateLimit      // LOGIN_LINK_IP_LIMIT / LOGIN_LINK_IP_WINDOW
+	Store    RateLimitStore // nil means an in-process MemoryRateLimitStore
+
+	// TrustProxyHeaders takes the client IP from X-Forwarded-For (set when running behind a proxy).
+	TrustProxyHeaders bool
+}
+
+// rateLimitedError is returned when a bucket is empty.
+type rateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate_limited: retry after %s", e.retryAfter)
+}
+
+// Unwrap lets httpx.WriteErr answer with errRateLimited.
+func (e *rateLimitedError) Unwrap() error {
+	return errRateLimited.WithDetails(map[string]int{"retryAfterSeconds": e.retryAfterSeconds()})
+}
+
+// retryAfterSeconds is the Retry-After value: whole seconds, at least 1.
+func (e *rateLimitedError) retryAfterSeconds() int {
+	return max(int(math.Ceil(e.retryAfter.Seconds())), 1)
+}
+
+// retryAfterWait returns how long until the bucket holds one token again.
+func retryAfterWait(tokens float64, limit RateLimit) time.Duration {
+	missing := 1 - tokens
+	return time.Duration(math.Ceil(missing / limit.refillPerSecond() * float64(time.Second)))
+}
+
+// MemoryRateLimitStore keeps buckets in process memory. Limits are per instance.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     RateLimit
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   map[string]*memoryBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Burst), updatedAt: now, limit: limit}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.refillPerSecond())
+	b.updatedAt = now
+	b.limit = limit
+
+	if b.tokens < 1 {
+		return false, retryAfterWait(b.tokens, limit), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute. Callers must hold s.mu.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= b.limit.Per {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// PostgresRateLimitStore keeps buckets in the rate_limit_buckets table so limits are
+// shared by every instance.
+type PostgresRateLimitStore struct {
+	pool      *pgxpool.Pool
+	retention time.Duration
+	logger    *slog.Logger
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+// NewPostgresRateLimitStore returns a store that deletes buckets idle for longer than
+// retention. A bucket idle for a whole window has refilled, so retention is the largest
+// window of the limits it serves.
+func NewPostgresRateLimitStore(pool *pgxpool.Pool, retention time.Duration, logger *slog.Logger) *PostgresRateLimitStore {
+	return &PostgresRateLimitStore{pool: pool, retention: retention, logger: logger}
+}
+
+func (s *PostgresRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	s.cleanup(ctx)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Create a full bucket on first use, then lock it; refill is computed with the database clock
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (key) DO NOTHING
+	`, key, float64(limit.Burst)); err != nil {
+		return false, 0, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var tokens float64
+	err = tx.QueryRow(ctx, `
+		SELECT LEAST($2, tokens + EXTRACT(EPOCH FROM now() - updated_at) * $3)
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key, float64(limit.Burst), limit.refillPerSecond()).Scan(&tokens)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $2, updated_at = now() WHERE key = $1`, key, tokens); err != nil {
+		return false, 0, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, fmt.Errorf("failed to commit rate limit bucket: %w", err)
+	}
+
+	if !allowed {
+		return false, retryAfterWait(tokens, limit), nil
+	}
+	return true, 0, nil
+}
+
+// cleanup deletes idle buckets at most once an hour per instance.
+func (s *PostgresRateLimitStore) cleanup(ctx context.Context) {
+	s.mu.Lock()
+	due := time.Since(s.lastCleanup) >= time.Hour
+	if due {
+		s.lastCleanup = time.Now()
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)`,
+		s.retention.Seconds())
+	if err != nil {
+		s.logger.WarnContext(ctx, "rate limit bucket cleanup failed", "error", err)
+	}
+}
@@ -2,8 +2,10 @@ package auth
 
 import (
 	"context"
+	"crypto/hmac"
 	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +14,12 @@ import (
 )
 
 type Repository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
 }
 
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+func NewRepository(pool *pgxpool.Pool, logger *slog.Logger) *Repository {
+	return &Repository{pool: pool, logger: logger}
 }
 
 // UpsertUserByEmail creates a user if they don't exist, or returns the existing user ID.
@@ -35,13 +38,14 @@ func (r *Repository) UpsertUserByEmail(ctx context.Context, email string) (uuid.
 	return userID, nil
 }
 
-// CreateLoginLink inserts a new login link with the given token hash and expiry.
-func (r *Repository) CreateLoginLink(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+// CreateLoginLink inserts a new login link with the given token hash, verifier hash and expiry.
+// codeHash is nil unless a one-time code was issued with the link.
+func (r *Repository) CreateLoginLink(ctx context.Context, userID uuid.UUID, tokenHash, verifierHash string, codeHash *string, expiresAt time.Time) error {
 	query := `
-		INSERT INTO login_links (user_id, token_hash, expires_at)
-		VALUES ($1, $2, $3)
+		INSERT INTO login_links (user_id, token_hash, verifier_hash, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
 	`
-	_, err := r.pool.Exec(ctx, query, userID, tokenHash, expiresAt)
+	_, err := r.pool.Exec(ctx, query, userID, tokenHash, verifierHash, codeHash, expiresAt)
 	if err != nil {
 		return fmt.Errorf("failed to create login link: %w", err)
 	}
@@ -49,37 +53,263 @@ func (r *Repository) CreateLoginLink(ctx context.Context, userID uuid.UUID, toke
 }
 
 // ConsumeLoginLink atomically marks a login link as used and returns the user ID.
-// Returns an error if the token is invalid, expired, or already used.
-func (r *Repository) ConsumeLoginLink(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+// Returns errInvalidOrExpiredToken if the token is invalid, expired, already used, or was
+// requested with a different verifier.
+func (r *Repository) ConsumeLoginLink(ctx context.Context, tokenHash, verifierHash string) (uuid.UUID, error) {
 	var userID uuid.UUID
+	// A wrong verifier matches no row, so a forwarded or prefetched link is not burned
 	query := `
 		UPDATE login_links
 		SET used_at = now()
 		WHERE token_hash = $1
+		  AND verifier_hash = $2
 		  AND used_at IS NULL
 		  AND expires_at > now()
 		RETURNING user_id
 	`
-	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&userID)
+	err := r.pool.QueryRow(ctx, query, tokenHash, verifierHash).Scan(&userID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return uuid.Nil, fmt.Errorf("invalid or expired token")
+			return uuid.Nil, errInvalidOrExpiredToken
 		}
 		return uuid.Nil, fmt.Errorf("failed to consume login link: %w", err)
 	}
 	return userID, nil
 }
 
-// GetUserByID retrieves a user's email by their ID.
-func (r *Repository) GetUserByID(ctx context.Context, userID uuid.UUID) (string, error) {
-	var email string
-	query := `SELECT email FROM users WHERE id = $1`
-	err := r.pool.QueryRow(ctx, query, userID).Scan(&email)
+// ConsumeLoginCode checks a one-time code against the user's most recent active login link
+// requested with the same verifier, and marks the link used on a match. Every mismatch is counted; once maxAttempts is reached
+// the code is locked (errLoginCodeLocked) and only the magic link or a new request works.
+func (r *Repository) ConsumeLoginCode(ctx context.Context, email, codeHash, verifierHash string, maxAttempts int) (uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var linkID, userID uuid.UUID
+	var storedHash string
+	var attempts int
+	err = tx.QueryRow(ctx, `
+		SELECT l.id, l.user_id, l.code_hash, l.code_attempts
+		FROM login_links l
+		JOIN users u ON u.id = l.user_id
+		WHERE u.email = $1
+		  AND l.verifier_hash = $2
+		  AND l.code_hash IS NOT NULL
+		  AND l.used_at IS NULL
+		  AND l.expires_at > now()
+		ORDER BY l.created_at DESC
+		LIMIT 1
+		FOR UPDATE OF l
+	`, email, verifierHash).Scan(&linkID, &userID, &storedHash, &attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, errInvalidLoginCode
+		}
+		return uuid.Nil, fmt.Errorf("failed to lock login link: %w", err)
+	}
+
+	if attempts >= maxAttempts {
+		r.logger.WarnContext(ctx, "login code locked", "login_link_id", linkID, "user_id", userID)
+		return uuid.Nil, errLoginCodeLocked
+	}
+
+	if !hmac.Equal([]byte(storedHash), []byte(codeHash)) {
+		if _, err := tx.Exec(ctx,
+			`UPDATE login_links SET code_attempts = code_attempts + 1 WHERE id = $1`, linkID); err != nil {
+			return uuid.Nil, fmt.Errorf("failed to count login code attempt: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return uuid.Nil, fmt.Errorf("failed to commit login code attempt: %w", err)
+		}
+		r.logger.WarnContext(ctx, "wrong login code", "login_link_id", linkID, "user_id", userID, "attempts", attempts+1)
+		return uuid.Nil, errInvalidLoginCode
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE login_links SET used_at = now() WHERE id = $1`, linkID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to consume login link: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit login code: %w", err)
+	}
+
+	return userID, nil
+}
+
+// GetUserByID retrieves a user's email and role by their ID.
+func (r *Repository) GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error) {
+	user := User{ID: userID.String()}
+	query := `SELECT email, role FROM users WHERE id = $1`
+	err := r.pool.QueryRow(ctx, query, userID).Scan(&user.Email, &user.Role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// CreateSession starts a session for the user together with its first refresh token
+// and returns the session ID. The session ID doubles as the refresh token family.
+func (r *Repository) CreateSession(ctx context.Context, userID uuid.UUID, refreshTokenHash string, expiresAt time.Time) (uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var sessionID uuid.UUID
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO sessions (user_id) VALUES ($1) RETURNING id`, userID).Scan(&sessionID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.Exec(ctx, query, userID, sessionID, refreshTokenHash, expiresAt); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit session: %w", err)
+	}
+
+	return sessionID, nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one in the same family and
+// returns the user and session IDs. A token that was already rotated is treated as
+// stolen: the session and its whole family are revoked (committed) and
+// errRefreshTokenReused is returned along with the owner's IDs.
+func (r *Repository) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, newExpiresAt time.Time) (uuid.UUID, uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID, familyID uuid.UUID
+	var expiresAt time.Time
+	var usedAt, revokedAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT user_id, family_id, expires_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(&userID, &familyID, &expiresAt, &usedAt, &revokedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", fmt.Errorf("user not found")
+			return uuid.Nil, uuid.Nil, errInvalidRefreshToken
 		}
-		return "", fmt.Errorf("failed to get user: %w", err)
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to lock refresh token: %w", err)
+	}
+
+	if usedAt != nil {
+		if _, err := revokeSessions(ctx, tx, `id = $1`, familyID); err != nil {
+			return uuid.Nil, uuid.Nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return uuid.Nil, uuid.Nil, fmt.Errorf("failed to commit refresh token revocation: %w", err)
+		}
+		return userID, familyID, errRefreshTokenReused
+	}
+
+	if revokedAt != nil || !expiresAt.After(time.Now()) {
+		return uuid.Nil, uuid.Nil, errInvalidRefreshToken
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE refresh_tokens SET used_at = now() WHERE token_hash = $1`, tokenHash); err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, familyID, newTokenHash, newExpiresAt); err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+
+	return userID, familyID, nil
+}
+
+// IsSessionRevoked reports whether the session was logged out. Unknown sessions count as revoked.
+func (r *Repository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return true, nil
+	}
+
+	var revoked bool
+	err = r.pool.QueryRow(ctx, `SELECT revoked_at IS NOT NULL FROM sessions WHERE id = $1`, id).Scan(&revoked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+	return revoked, nil
+}
+
+// RevokeSession logs out one of the user's sessions and revokes its refresh tokens.
+func (r *Repository) RevokeSession(ctx context.Context, sessionID, userID uuid.UUID) error {
+	_, err := r.revokeSessionsTx(ctx, `id = $1 AND user_id = $2`, sessionID, userID)
+	return err
+}
+
+// RevokeUserSessions logs out every session of the user and returns the IDs it revoked.
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return r.revokeSessionsTx(ctx, `user_id = $1`, userID)
+}
+
+func (r *Repository) revokeSessionsTx(ctx context.Context, where string, args ...any) ([]uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, err := revokeSessions(ctx, tx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+	r.logger.DebugContext(ctx, "sessions revoked", "count", len(ids))
+	return ids, nil
+}
+
+// revokeSessions marks the active sessions matching where, and their refresh tokens,
+// as revoked and returns the session IDs.
+func revokeSessions(ctx context.Context, tx pgx.Tx, where string, args ...any) ([]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE sessions SET revoked_at = now()
+		WHERE revoked_at IS NULL AND `+where+`
+		RETURNING id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = now()
+		WHERE family_id = ANY($1) AND revoked_at IS NULL
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
 	}
-	return email, nil
+	return ids, nil
 }
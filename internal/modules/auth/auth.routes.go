@@ -1,18 +1,31 @@
 package auth
 
 import (
+	"log/slog"
 	"net/http"
 
+	"feedback/internal/mail"
+	"feedback/internal/middleware"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // RegisterRoutes registers all auth routes on the provided mux.
-func RegisterRoutes(mux *http.ServeMux, pool *pgxpool.Pool, jwtSecret, deeplinkURL string, mailConfig MailConfig) {
-	repo := NewRepository(pool)
-	service := NewService(repo, jwtSecret, deeplinkURL, mailConfig)
-	handler := NewHandler(service)
+// sessions is shared with the other modules' RequireAuth so logouts take effect immediately on this instance.
+func RegisterRoutes(mux *http.ServeMux, pool *pgxpool.Pool, jwtSecret, deeplinkURL string, mailer mail.Mailer, templates *mail.Templates, tokenConfig TokenConfig, sessions *middleware.SessionCache, rateLimits RateLimitConfig, logger *slog.Logger) {
+	logger = logger.With("module", "auth")
+
+	repo := NewRepository(pool, logger)
+	service := NewService(repo, jwtSecret, deeplinkURL, mailer, templates, tokenConfig, sessions, rateLimits, logger)
+	handler := NewHandler(service, logger)
+
+	requireAuth := middleware.RequireAuth(jwtSecret, sessions, logger)
 
 	mux.HandleFunc("/auth/login-link", handler.HandleRequestLoginLink)
 	mux.HandleFunc("/auth/login-link/verify", handler.HandleVerifyLoginLink)
+	mux.HandleFunc("/auth/login-code/verify", handler.HandleVerifyLoginCode)
+	mux.HandleFunc("/auth/refresh", handler.HandleRefreshTokens)
+	mux.HandleFunc("/auth/logout", requireAuth(handler.HandleLogout))
+	mux.HandleFunc("/auth/logout-all", requireAuth(handler.HandleLogoutAll))
 	mux.HandleFunc("/auth/deeplink", handler.HandleDeeplink)
 }
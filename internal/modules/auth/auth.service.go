@@ -2,34 +2,92 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
+
+	"feedback/internal/mail"
+	"feedback/internal/middleware"
+
+	"github.com/google/uuid"
 )
 
+const (
+	// loginLinkTTL is how long a magic link (and its code) stays valid.
+	loginLinkTTL = 15 * time.Minute
+	// loginCodeMaxAttempts is how many wrong codes lock a login link's code.
+	loginCodeMaxAttempts = 5
+)
+
+// TokenConfig sets the lifetimes of issued tokens. Zero values fall back to the defaults below.
+type TokenConfig struct {
+	AccessTTL  time.Duration // ACCESS_TOKEN_TTL, lifetime of the JWT
+	RefreshTTL time.Duration // REFRESH_TOKEN_TTL, lifetime of each refresh token
+}
+
+func (c TokenConfig) withDefaults() TokenConfig {
+	if c.AccessTTL <= 0 {
+		c.AccessTTL = 15 * time.Minute
+	}
+	if c.RefreshTTL <= 0 {
+		c.RefreshTTL = 30 * 24 * time.Hour
+	}
+	return c
+}
+
 type Service struct {
 	repo        *Repository
 	jwtSecret   string
-	mailConfig  MailConfig
+	mailer      mail.Mailer
+	templates   *mail.Templates
 	deeplinkURL string
+	tokens      TokenConfig
+	sessions    *middleware.SessionCache
+	rateLimits  RateLimitConfig
+	logger      *slog.Logger
 }
 
-func NewService(repo *Repository, jwtSecret, deeplinkURL string, mailConfig MailConfig) *Service {
+func NewService(repo *Repository, jwtSecret, deeplinkURL string, mailer mail.Mailer, templates *mail.Templates, tokens TokenConfig, sessions *middleware.SessionCache, rateLimits RateLimitConfig, logger *slog.Logger) *Service {
+	if rateLimits.Store == nil {
+		rateLimits.Store = NewMemoryRateLimitStore()
+	}
 	return &Service{
 		repo:        repo,
 		jwtSecret:   jwtSecret,
-		mailConfig:  mailConfig,
+		mailer:      mailer,
+		templates:   templates,
 		deeplinkURL: deeplinkURL,
+		tokens:      tokens.withDefaults(),
+		sessions:    sessions,
+		rateLimits:  rateLimits,
+		logger:      logger,
 	}
 }
 
 // RequestLoginLink handles the login link request flow.
-// It normalizes the email, upserts the user, generates a token, stores it, and sends the email.
-func (s *Service) RequestLoginLink(ctx context.Context, email string) error {
+// It normalizes the email, applies the per-IP and per-email rate limits, upserts the user,
+// generates a token (and a 6-digit code if req.IncludeCode), stores their hashes together with
+// the client's code challenge, and sends the email in the first supported locale of locales.
+func (s *Service) RequestLoginLink(ctx context.Context, req RequestLoginLinkRequest, clientIP string, locales []string) error {
 	// Normalize email
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	normalizedEmail := normalizeEmail(req.Email)
 	if normalizedEmail == "" {
-		return fmt.Errorf("email is required")
+		return errEmailRequired
+	}
+
+	// The link can only be redeemed with the verifier behind this challenge
+	if !ValidCodeChallenge(req.CodeChallenge) {
+		return errInvalidCodeChallenge
+	}
+
+	// Rate limit before touching the database or Mailgun
+	if err := s.takeRateLimit(ctx, "ip", clientIP, s.rateLimits.PerIP); err != nil {
+		return err
+	}
+	if err := s.takeRateLimit(ctx, "email", normalizedEmail, s.rateLimits.PerEmail); err != nil {
+		return err
 	}
 
 	// Upsert user
@@ -47,53 +105,198 @@ func (s *Service) RequestLoginLink(ctx context.Context, email string) error {
 	// Hash token for storage
 	tokenHash := HashToken(rawToken)
 
+	// Optional one-time code for devices that can't open the deeplink
+	var code string
+	var codeHash *string
+	if req.IncludeCode {
+		code, err = GenerateLoginCode()
+		if err != nil {
+			return err
+		}
+		hash := HashLoginCode(s.jwtSecret, normalizedEmail, code)
+		codeHash = &hash
+	}
+
 	// Create login link with 15 minute expiry
-	expiresAt := time.Now().Add(15 * time.Minute)
-	if err := s.repo.CreateLoginLink(ctx, userID, tokenHash, expiresAt); err != nil {
+	expiresAt := time.Now().Add(loginLinkTTL)
+	if err := s.repo.CreateLoginLink(ctx, userID, tokenHash, req.CodeChallenge, codeHash, expiresAt); err != nil {
 		return fmt.Errorf("failed to create login link: %w", err)
 	}
 
-	// Send email (do NOT log raw token)
-	if err := SendLoginLink(s.mailConfig, normalizedEmail, s.deeplinkURL, rawToken); err != nil {
-		fmt.Printf("SendLoginLink error: %v\n", err)
-		return fmt.Errorf("email_send_failed: %w", err)
+	// Send email (do NOT log raw token or code)
+	msg, err := loginLinkMessage(s.templates, normalizedEmail, s.deeplinkURL, rawToken, code, locales)
+	if err != nil {
+		return fmt.Errorf("failed to build login link email: %w", err)
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return errEmailSendFailed.Wrap(err)
+	}
+
+	s.logger.InfoContext(ctx, "login link sent", "user_id", userID, "include_code", req.IncludeCode)
+	return nil
+}
+
+// takeRateLimit consumes a token from the login-link bucket for scope ("ip" or "email") and value,
+// returning a *rateLimitedError when empty. Store failures are logged and let the request through.
+func (s *Service) takeRateLimit(ctx context.Context, scope, value string, limit RateLimit) error {
+	if limit.Burst <= 0 || limit.Per <= 0 {
+		return nil
 	}
 
+	ok, retryAfter, err := s.rateLimits.Store.Take(ctx, "login-link:"+scope+":"+value, limit)
+	if err != nil {
+		s.logger.WarnContext(ctx, "rate limit check failed, allowing request", "error", err)
+		return nil
+	}
+	if !ok {
+		s.logger.InfoContext(ctx, "login link rate limited", "scope", scope, "retry_after", retryAfter)
+		return &rateLimitedError{retryAfter: retryAfter}
+	}
 	return nil
 }
 
-// VerifyLoginLink verifies the token and returns a JWT and user info.
-func (s *Service) VerifyLoginLink(ctx context.Context, rawToken string) (*VerifyLoginLinkResponse, error) {
+// VerifyLoginLink verifies the token, starts a session and returns a JWT, a refresh token and user info.
+func (s *Service) VerifyLoginLink(ctx context.Context, rawToken, codeVerifier string) (*VerifyLoginLinkResponse, error) {
 	if rawToken == "" {
-		return nil, fmt.Errorf("token is required")
+		return nil, errInvalidOrExpiredToken
+	}
+	if !ValidCodeVerifier(codeVerifier) {
+		return nil, errInvalidCodeVerifier
 	}
 
 	// Hash the token
 	tokenHash := HashToken(rawToken)
 
-	// Atomically consume the login link
-	userID, err := s.repo.ConsumeLoginLink(ctx, tokenHash)
+	// Atomically consume the login link requested with this verifier
+	userID, err := s.repo.ConsumeLoginLink(ctx, tokenHash, CodeChallengeS256(codeVerifier))
 	if err != nil {
-		return nil, fmt.Errorf("invalid_or_expired_token")
+		return nil, err
 	}
 
-	// Get user email
-	email, err := s.repo.GetUserByID(ctx, userID)
+	return s.startSession(ctx, userID)
+}
+
+// VerifyLoginCode checks the one-time code sent with the user's latest login link and,
+// on a match, starts a session like VerifyLoginLink.
+func (s *Service) VerifyLoginCode(ctx context.Context, email, code, codeVerifier string) (*VerifyLoginLinkResponse, error) {
+	normalizedEmail := normalizeEmail(email)
+	code = strings.TrimSpace(code)
+	if normalizedEmail == "" || code == "" {
+		return nil, errInvalidLoginCode
+	}
+	if !ValidCodeVerifier(codeVerifier) {
+		return nil, errInvalidCodeVerifier
+	}
+
+	codeHash := HashLoginCode(s.jwtSecret, normalizedEmail, code)
+	userID, err := s.repo.ConsumeLoginCode(ctx, normalizedEmail, codeHash, CodeChallengeS256(codeVerifier), loginCodeMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.startSession(ctx, userID)
+}
+
+// startSession creates a session for a user who just proved ownership of their email
+// and returns the access token, the first refresh token and the user.
+func (s *Service) startSession(ctx context.Context, userID uuid.UUID) (*VerifyLoginLinkResponse, error) {
+	// Get user email and role
+	user, err := s.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// Start a session with its first refresh token
+	refreshToken, err := GenerateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	expiresAt := time.Now().Add(s.tokens.RefreshTTL)
+	sessionID, err := s.repo.CreateSession(ctx, userID, HashToken(refreshToken), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create JWT
-	jwt, err := CreateJWT(userID.String(), email, s.jwtSecret)
+	jwt, err := CreateJWT(userID.String(), user.Email, user.Role, sessionID.String(), s.jwtSecret, s.tokens.AccessTTL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create JWT: %w", err)
 	}
 
+	s.logger.InfoContext(ctx, "session started", "user_id", userID, "session_id", sessionID)
 	return &VerifyLoginLinkResponse{
-		AccessToken: jwt,
-		User: User{
-			ID:    userID.String(),
-			Email: email,
-		},
+		AccessToken:  jwt,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(s.tokens.AccessTTL.Seconds()),
+		User:         *user,
 	}, nil
 }
+
+// RefreshTokens rotates a refresh token and returns a new access/refresh token pair.
+// Presenting a token that was already rotated revokes every token in its family.
+func (s *Service) RefreshTokens(ctx context.Context, rawToken string) (*RefreshTokenResponse, error) {
+	if rawToken == "" {
+		return nil, errInvalidRefreshToken
+	}
+
+	newToken, err := GenerateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.tokens.RefreshTTL)
+	userID, sessionID, err := s.repo.RotateRefreshToken(ctx, HashToken(rawToken), HashToken(newToken), expiresAt)
+	if err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			// Do NOT log the raw token
+			s.logger.WarnContext(ctx, "refresh token reuse detected, session revoked", "user_id", userID, "session_id", sessionID)
+			s.sessions.Revoke(sessionID.String())
+		}
+		return nil, err
+	}
+
+	// Re-read the role so promotions and demotions apply from the next refresh
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	jwt, err := CreateJWT(userID.String(), user.Email, user.Role, sessionID.String(), s.jwtSecret, s.tokens.AccessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT: %w", err)
+	}
+
+	return &RefreshTokenResponse{
+		AccessToken:  jwt,
+		RefreshToken: newToken,
+		ExpiresIn:    int(s.tokens.AccessTTL.Seconds()),
+	}, nil
+}
+
+// Logout revokes the given session: its access tokens stop working and its refresh tokens are revoked.
+func (s *Service) Logout(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if err := s.repo.RevokeSession(ctx, sessionID, userID); err != nil {
+		return err
+	}
+	s.sessions.Revoke(sessionID.String())
+	s.logger.InfoContext(ctx, "logged out", "session_id", sessionID)
+	return nil
+}
+
+// LogoutAll revokes every session of the user and returns how many were active.
+func (s *Service) LogoutAll(ctx context.Context, userID uuid.UUID) (int, error) {
+	ids, err := s.repo.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		s.sessions.Revoke(id.String())
+	}
+	s.logger.InfoContext(ctx, "logged out of all sessions", "sessions_revoked", len(ids))
+	return len(ids), nil
+}
+
+// normalizeEmail lowercases and trims an email address.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,10 +1,13 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 )
 
 // GenerateToken generates a cryptographically secure random token.
@@ -22,3 +25,56 @@ func HashToken(rawToken string) string {
 	hash := sha256.Sum256([]byte(rawToken))
 	return fmt.Sprintf("%x", hash)
 }
+
+// GenerateLoginCode generates a uniformly random 6-digit one-time code.
+func GenerateLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// HashLoginCode returns the HMAC-SHA256 of the code, keyed by secret and bound to the
+// email, as a hex string. A plain hash of a 6-digit code would be trivial to reverse.
+func HashLoginCode(secret, email, code string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(email + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// pkceChallengeLen is the length of a base64url (unpadded) SHA-256 digest.
+const pkceChallengeLen = 43
+
+// ValidCodeChallenge reports whether challenge looks like a PKCE S256 challenge:
+// the unpadded base64url SHA-256 of the client's verifier.
+func ValidCodeChallenge(challenge string) bool {
+	if len(challenge) != pkceChallengeLen {
+		return false
+	}
+	_, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil
+}
+
+// ValidCodeVerifier reports whether verifier follows RFC 7636: 43-128 characters
+// from [A-Za-z0-9-._~].
+func ValidCodeVerifier(verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// CodeChallengeS256 returns the PKCE S256 challenge of verifier.
+func CodeChallengeS256(verifier string) string {
+	hash := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
@@ -9,7 +9,13 @@ import (
 // Request/Response types
 
 type RequestLoginLinkRequest struct {
-	Email string `json:"email"`
+	Email       string `json:"email" validate:"required,email,max=254"`
+	Locale      string `json:"locale,omitempty" validate:"max=35"` // e.g. "es"; overrides Accept-Language
+	IncludeCode bool   `json:"includeCode,omitempty"`              // also email a 6-digit code for POST /auth/login-code/verify
+
+	// CodeChallenge is base64url(SHA-256(codeVerifier)); the verifier stays on the device
+	// and must be sent to redeem the link or code (PKCE S256).
+	CodeChallenge string `json:"codeChallenge" validate:"required,len=43"`
 }
 
 type RequestLoginLinkResponse struct {
@@ -17,12 +23,40 @@ type RequestLoginLinkResponse struct {
 }
 
 type VerifyLoginLinkRequest struct {
-	Token string `json:"token"`
+	Token        string `json:"token" validate:"required,max=128"`
+	CodeVerifier string `json:"codeVerifier" validate:"required,min=43,max=128"` // the verifier behind the request's codeChallenge
 }
 
 type VerifyLoginLinkResponse struct {
-	AccessToken string `json:"accessToken"`
-	User        User   `json:"user"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // access token lifetime in seconds
+	User         User   `json:"user"`
+}
+
+type VerifyLoginCodeRequest struct {
+	Email        string `json:"email" validate:"required,email,max=254"`
+	Code         string `json:"code" validate:"required,len=6"`
+	CodeVerifier string `json:"codeVerifier" validate:"required,min=43,max=128"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required,max=128"`
+}
+
+type RefreshTokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // access token lifetime in seconds
+}
+
+type LogoutResponse struct {
+	OK bool `json:"ok"`
+}
+
+type LogoutAllResponse struct {
+	OK              bool `json:"ok"`
+	SessionsRevoked int  `json:"sessionsRevoked"`
 }
 
 // Domain types
@@ -30,6 +64,7 @@ type VerifyLoginLinkResponse struct {
 type User struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
+	Role  string `json:"role"` // user or admin
 }
 
 type LoginLink struct {
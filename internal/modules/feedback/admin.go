@@ -0,0 +1,158 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ParseAdminFilter reads the admin inbox filters from query parameters:
+// q, user_id, email, from, to, category, status and assignee_id. from and to take an RFC 3339
+// time or a date (YYYY-MM-DD); a date in to includes that whole day (UTC).
+// assignee_id=none selects unassigned feedback.
+func ParseAdminFilter(query url.Values) (AdminFeedbackFilter, error) {
+	var filter AdminFeedbackFilter
+
+	filter.Query = strings.TrimSpace(query.Get("q"))
+	if utf8.RuneCountInString(filter.Query) > MaxSearchQueryRunes {
+		return filter, filterError("q", fmt.Sprintf("must be at most %d characters", MaxSearchQueryRunes))
+	}
+
+	if v := query.Get("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, filterError("user_id", "must be a UUID")
+		}
+		filter.UserID = &id
+	}
+	filter.UserEmail = query.Get("email")
+
+	switch v := query.Get("assignee_id"); v {
+	case "":
+	case "none":
+		filter.Unassigned = true
+	default:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return filter, filterError("assignee_id", "must be a UUID or none")
+		}
+		filter.AssigneeID = &id
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		v := query.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			day, dayErr := time.Parse(time.DateOnly, v)
+			if dayErr != nil {
+				return filter, filterError(p.name, "must be an RFC 3339 time or a YYYY-MM-DD date")
+			}
+			t = day
+			if p.name == "to" {
+				t = day.AddDate(0, 0, 1)
+			}
+		}
+		*p.dst = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, filterError("to", "must be after from")
+	}
+
+	switch filter.Category = query.Get("category"); filter.Category {
+	case "", CategoryBug, CategoryIdea, CategoryPraise:
+	default:
+		return filter, filterError("category", "must be one of: bug, idea, praise")
+	}
+	switch filter.Status = query.Get("status"); filter.Status {
+	case "", StatusNew, StatusTriaged, StatusInProgress, StatusResolved, StatusWontFix:
+	default:
+		return filter, filterError("status", "must be one of: new, triaged, in_progress, resolved, wont_fix")
+	}
+
+	return filter, nil
+}
+
+// filterError reports one invalid query parameter of the admin inbox.
+func filterError(param, reason string) error {
+	return errInvalidFilter.WithDetails(map[string]string{"param": param, "reason": reason})
+}
+
+// MaxSearchQueryRunes caps the admin inbox search query (?q=).
+const MaxSearchQueryRunes = 200
+
+// ListAllFeedback returns one page of every user's feedback matching filter, newest first,
+// or most relevant first when filter.Query is set. Paging works as in ListFeedback; the
+// cursors of a search only work with the same search.
+func (s *Service) ListAllFeedback(ctx context.Context, filter AdminFeedbackFilter, limit int, before, after string) (*AdminListFeedbackResponse, error) {
+	limit, beforeCursor, afterCursor, err := parsePage(limit, before, after)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range []*Cursor{beforeCursor, afterCursor} {
+		if c != nil && (c.Rank != nil) != (filter.Query != "") {
+			return nil, errInvalidCursor.WithDetails(map[string]string{"reason": "cursor does not belong to this search"})
+		}
+	}
+
+	items, err := s.repo.ListAll(ctx, filter, limit+1, beforeCursor, afterCursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	resp := &AdminListFeedbackResponse{}
+	resp.Items, resp.NextCursor, resp.PrevCursor = pageOf(items, limit, beforeCursor, afterCursor, func(a AdminFeedback) string {
+		c := Cursor{CreatedAt: a.CreatedAt, ID: uuid.MustParse(a.ID), Rank: a.rank}
+		return c.Encode()
+	})
+	return resp, nil
+}
+
+// GetAnyFeedback returns any user's feedback item with its edit history, attachments,
+// replies, internal notes and audit trail.
+func (s *Service) GetAnyFeedback(ctx context.Context, id uuid.UUID) (*AdminFeedbackDetail, error) {
+	f, err := s.repo.GetAny(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	edits, err := s.repo.ListEdits(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback edits: %w", err)
+	}
+
+	attachments, err := s.repo.ListAttachments(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback attachments: %w", err)
+	}
+	for i := range attachments {
+		s.signAttachment(&attachments[i])
+	}
+
+	replies, err := s.repo.ListReplies(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback replies: %w", err)
+	}
+
+	notes, err := s.repo.ListNotes(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback notes: %w", err)
+	}
+
+	history, err := s.repo.ListAudit(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback history: %w", err)
+	}
+
+	return &AdminFeedbackDetail{AdminFeedback: *f, Edits: edits, Attachments: attachments, Replies: replies, Notes: notes, History: history}, nil
+}
@@ -0,0 +1,238 @@
+package feedback
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"feedback/internal/shared/httpx"
+
+	"github.com/google/uuid"
+)
+
+// HandleAdminListFeedback handles GET /admin/feedback (admin only)
+func (h *Handler) HandleAdminListFeedback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			httpx.WriteErr(w, errInvalidLimit)
+			return
+		}
+	}
+
+	filter, err := ParseAdminFilter(query)
+	if err != nil {
+		httpx.WriteErr(w, err)
+		return
+	}
+
+	resp, err := h.service.ListAllFeedback(r.Context(), filter, limit, query.Get("before"), query.Get("after"))
+	if err != nil {
+		h.writeErr(w, r, "admin list feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleAdminGetFeedback handles GET /admin/feedback/{id} (admin only)
+func (h *Handler) HandleAdminGetFeedback(w http.ResponseWriter, r *http.Request) {
+	id, ok := feedbackID(w, r)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetAnyFeedback(r.Context(), id)
+	if err != nil {
+		h.writeErr(w, r, "admin get feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, detail)
+}
+
+// exportWriteTimeout replaces the server's WriteTimeout for exports, which stream for
+// as long as the client keeps reading.
+const exportWriteTimeout = 10 * time.Minute
+
+// HandleAdminExportFeedback handles GET /admin/feedback/export?format=csv|ndjson (admin only).
+// It takes the filters of GET /admin/feedback and streams every match, oldest first.
+func (h *Handler) HandleAdminExportFeedback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = ExportCSV
+	}
+	enc, err := newExportEncoder(format, w)
+	if err != nil {
+		httpx.WriteErr(w, err)
+		return
+	}
+
+	filter, err := ParseAdminFilter(query)
+	if err != nil {
+		httpx.WriteErr(w, err)
+		return
+	}
+
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(exportWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(r.Context(), "failed to extend export write deadline", "error", err)
+	}
+
+	// Headers go out with the first row, so a query that fails up front still gets a JSON error
+	started := false
+	begin := func() error {
+		started = true
+		filename := fmt.Sprintf("feedback-%s.%s", time.Now().UTC().Format(time.DateOnly), format)
+		w.Header().Set("Content-Type", enc.ContentType())
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		return enc.Begin()
+	}
+
+	err = h.service.ExportFeedback(r.Context(), filter, func(a *AdminFeedback) error {
+		if !started {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+		return enc.Encode(a)
+	})
+	if err == nil && !started {
+		err = begin()
+	}
+	if err == nil {
+		err = enc.End()
+	}
+	if err == nil {
+		return
+	}
+
+	if !started {
+		h.writeErr(w, r, "admin export feedback failed", err)
+		return
+	}
+	if r.Context().Err() == nil {
+		h.logger.ErrorContext(r.Context(), "admin export feedback failed", "error", err)
+	}
+	// The status is already sent; drop the connection so the client cannot mistake
+	// the partial file for a complete export.
+	panic(http.ErrAbortHandler)
+}
+
+// adminAction reads the acting admin and the {id} path value of a triage request.
+func adminAction(w http.ResponseWriter, r *http.Request) (actorID, id uuid.UUID, ok bool) {
+	if actorID, _, ok = authUser(w, r); !ok {
+		return
+	}
+	id, ok = feedbackID(w, r)
+	return
+}
+
+// HandleAdminSetStatus handles PUT /admin/feedback/{id}/status (admin only)
+func (h *Handler) HandleAdminSetStatus(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateStatusRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.service.ChangeStatus(r.Context(), id, actorID, req.Status)
+	if err != nil {
+		h.writeErr(w, r, "change feedback status failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, updated)
+}
+
+// HandleAdminAssign handles PUT /admin/feedback/{id}/assignee (admin only)
+func (h *Handler) HandleAdminAssign(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req AssignFeedbackRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.service.Assign(r.Context(), id, actorID, req.AssigneeID)
+	if err != nil {
+		h.writeErr(w, r, "assign feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, updated)
+}
+
+// HandleAdminUnassign handles DELETE /admin/feedback/{id}/assignee (admin only)
+func (h *Handler) HandleAdminUnassign(w http.ResponseWriter, r *http.Request) {
+	actorID, id, ok := adminAction(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.service.Unassign(r.Context(), id, actorID)
+	if err != nil {
+		h.writeErr(w, r, "unassign feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, updated)
+}
+
+// HandleAdminAddNote handles POST /admin/feedback/{id}/notes (admin only)
+func (h *Handler) HandleAdminAddNote(w http.ResponseWriter, r *http.Request) {
+	authorID, id, ok := adminAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateNoteRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	note, err := h.service.AddNote(r.Context(), id, authorID, req.Body)
+	if err != nil {
+		h.writeErr(w, r, "add feedback note failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, note)
+}
+
+// HandleAdminReply handles POST /admin/feedback/{id}/replies (admin only)
+func (h *Handler) HandleAdminReply(w http.ResponseWriter, r *http.Request) {
+	authorID, id, ok := adminAction(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateReplyRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	resp, err := h.service.Reply(r.Context(), id, authorID, req.Body)
+	if err != nil {
+		h.writeErr(w, r, "reply to feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, resp)
+}
@@ -0,0 +1,192 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// adminFeedbackColumns is feedbackColumns on feedback f, plus status, the submitter's email
+// from users u and the assignee from users a; selected FROM adminFeedbackFrom and scanned
+// by scanAdminFeedback.
+const adminFeedbackColumns = `f.id, f.user_id, f.message, f.created_at, f.updated_at,
+	f.category, f.rating, f.app_version, f.platform, f.os_version, f.metadata,
+	f.status, u.email, f.assignee_id, a.email`
+
+const adminFeedbackFrom = `feedback f
+		JOIN users u ON u.id = f.user_id
+		LEFT JOIN users a ON a.id = f.assignee_id`
+
+// scanAdminFeedback scans a row selected with adminFeedbackColumns, followed by any extra columns.
+func scanAdminFeedback(row pgx.Row, extra ...any) (*AdminFeedback, error) {
+	var a AdminFeedback
+	var assigneeID *uuid.UUID
+	f, err := scanFeedback(row, append([]any{&a.Status, &a.UserEmail, &assigneeID, &a.AssigneeEmail}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	a.Feedback = *f
+	if assigneeID != nil {
+		id := assigneeID.String()
+		a.AssigneeID = &id
+	}
+	return &a, nil
+}
+
+// queryArgs collects positional query arguments while a statement is assembled.
+type queryArgs []any
+
+// add appends v and returns its placeholder, e.g. "$3".
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// searchConfig is the text search configuration of feedback.search_vector (016_feedback_search.sql).
+const searchConfig = "'english'"
+
+// searchHeadlineOptions shape the snippet of a search result: up to two fragments of the
+// message around the matches, each match wrapped in <mark>.
+const searchHeadlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "'`
+
+// adminConditions returns the WHERE conditions for filter on feedback f joined with users u.
+// A search matches the message's words (websearch_to_tsquery, stemmed) or, for typos,
+// any word similar enough to the query (pg_trgm's <% operator, word_similarity_threshold).
+func adminConditions(filter AdminFeedbackFilter, args *queryArgs) []string {
+	conds := []string{"TRUE"}
+	if filter.Query != "" {
+		q := args.add(filter.Query)
+		conds = append(conds, "(f.search_vector @@ websearch_to_tsquery("+searchConfig+", "+q+") OR "+q+" <% f.message)")
+	}
+	if filter.UserID != nil {
+		conds = append(conds, "f.user_id = "+args.add(*filter.UserID))
+	}
+	if filter.UserEmail != "" {
+		conds = append(conds, "lower(u.email) = lower("+args.add(filter.UserEmail)+")")
+	}
+	if filter.From != nil {
+		conds = append(conds, "f.created_at >= "+args.add(*filter.From))
+	}
+	if filter.To != nil {
+		conds = append(conds, "f.created_at < "+args.add(*filter.To))
+	}
+	if filter.Category != "" {
+		conds = append(conds, "f.category = "+args.add(filter.Category))
+	}
+	if filter.Status != "" {
+		conds = append(conds, "f.status = "+args.add(filter.Status))
+	}
+	switch {
+	case filter.Unassigned:
+		conds = append(conds, "f.assignee_id IS NULL")
+	case filter.AssigneeID != nil:
+		conds = append(conds, "f.assignee_id = "+args.add(*filter.AssigneeID))
+	}
+	return conds
+}
+
+// ListAll returns up to limit feedback items of every user matching filter, newest first.
+// With a search query the items are ordered by rank instead (whole-word matches before
+// typo matches) and carry a snippet; cursors then hold the rank too. Cursors work as in ListByUser.
+func (r *Repository) ListAll(ctx context.Context, filter AdminFeedbackFilter, limit int, before, after *Cursor) ([]AdminFeedback, error) {
+	var args queryArgs
+	conds := adminConditions(filter, &args)
+
+	from := adminFeedbackFrom
+	key := []string{"f.created_at", "f.id"}
+	columns := adminFeedbackColumns
+	if filter.Query != "" {
+		// Full-text matches rank in [1, 2) by ts_rank_cd (normalized to rank/(rank+1));
+		// trigram-only matches rank below by their word similarity (at most 1).
+		q := args.add(filter.Query)
+		from += `
+		CROSS JOIN LATERAL (SELECT websearch_to_tsquery(` + searchConfig + `, ` + q + `) AS query) s
+		CROSS JOIN LATERAL (SELECT (CASE
+			WHEN f.search_vector @@ s.query THEN 1 + ts_rank_cd(f.search_vector, s.query, 32)
+			ELSE word_similarity(` + q + `, f.message)
+		END)::real AS rank) r`
+		key = append([]string{"r.rank"}, key...)
+		columns += `, r.rank,
+	ts_headline(` + searchConfig + `, replace(replace(replace(f.message, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), s.query, ` + searchHeadlineOptions + `)`
+	}
+	keyOf := func(c *Cursor) string {
+		vals := []string{args.add(c.CreatedAt), args.add(c.ID)}
+		if c.Rank != nil {
+			vals = append([]string{args.add(*c.Rank)}, vals...)
+		}
+		return "(" + strings.Join(vals, ", ") + ")"
+	}
+
+	order := "DESC"
+	switch {
+	case before != nil:
+		conds = append(conds, "("+strings.Join(key, ", ")+") < "+keyOf(before))
+	case after != nil:
+		// Walk forward from the cursor, then flip to newest first below.
+		conds = append(conds, "("+strings.Join(key, ", ")+") > "+keyOf(after))
+		order = "ASC"
+	}
+
+	query := `
+		SELECT ` + columns + `
+		FROM ` + from + `
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY ` + strings.Join(key, " "+order+", ") + ` ` + order + `
+		LIMIT ` + args.add(limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	defer rows.Close()
+
+	items := []AdminFeedback{}
+	for rows.Next() {
+		var a *AdminFeedback
+		if filter.Query != "" {
+			var rank float32
+			var snippet string
+			if a, err = scanAdminFeedback(rows, &rank, &snippet); err == nil {
+				a.rank, a.Snippet = &rank, snippet
+			}
+		} else {
+			a, err = scanAdminFeedback(rows)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		items = append(items, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	if after != nil {
+		slices.Reverse(items)
+	}
+
+	return items, nil
+}
+
+// GetAny returns any user's feedback item with its status and submitter email.
+func (r *Repository) GetAny(ctx context.Context, id uuid.UUID) (*AdminFeedback, error) {
+	query := `
+		SELECT ` + adminFeedbackColumns + `
+		FROM ` + adminFeedbackFrom + `
+		WHERE f.id = $1
+	`
+	a, err := scanAdminFeedback(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	return a, nil
+}
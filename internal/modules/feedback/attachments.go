@@ -0,0 +1,175 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"feedback/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// allowedAttachmentTypes are the sniffed content types accepted for upload.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// maxFilenameRunes bounds stored attachment filenames.
+const maxFilenameRunes = 255
+
+// AddAttachment stores an uploaded file on the user's feedback. The content type is sniffed
+// from the first 512 bytes (the client's claim is ignored) and the size is capped at
+// AttachmentMaxBytes while streaming to blob storage.
+func (s *Service) AddAttachment(ctx context.Context, feedbackID, userID uuid.UUID, filename string, body io.Reader) (*Attachment, error) {
+	// Check ownership before storing anything
+	if _, err := s.repo.GetByID(ctx, feedbackID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, uploadError(err)
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, errAttachmentEmpty
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !allowedAttachmentTypes[contentType] {
+		return nil, errAttachmentType.WithDetails(map[string]string{"detectedType": contentType})
+	}
+
+	id := uuid.New()
+	key := fmt.Sprintf("feedback/%s/%s", feedbackID, id)
+	counter := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), body), s.cfg.AttachmentMaxBytes+1)}
+	if err := s.store.Put(ctx, key, contentType, counter); err != nil {
+		s.deleteBlob(ctx, key)
+		return nil, uploadError(err)
+	}
+	if counter.n > s.cfg.AttachmentMaxBytes {
+		s.deleteBlob(ctx, key)
+		return nil, errAttachmentTooLarge.WithDetails(map[string]int64{"maxBytes": s.cfg.AttachmentMaxBytes})
+	}
+
+	a, err := s.repo.CreateAttachment(ctx, id, feedbackID, userID, key, cleanFilename(filename), contentType, counter.n, s.cfg.MaxAttachments)
+	if err != nil {
+		s.deleteBlob(ctx, key)
+		return nil, fmt.Errorf("failed to add attachment: %w", err)
+	}
+
+	s.signAttachment(a)
+	s.logger.InfoContext(ctx, "attachment added", "feedback_id", feedbackID, "attachment_id", a.ID, "content_type", contentType, "size_bytes", a.SizeBytes)
+	return a, nil
+}
+
+// OpenAttachment checks a signed download URL and returns the attachment with its content.
+// The caller must close the reader.
+func (s *Service) OpenAttachment(ctx context.Context, id uuid.UUID, expires, signature string) (*Attachment, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !hmac.Equal([]byte(signature), []byte(s.downloadSignature(id.String(), expiresAt))) {
+		return nil, nil, errInvalidDownloadURL
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, nil, errDownloadURLExpired
+	}
+
+	a, err := s.repo.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.store.Open(ctx, a.storageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, errAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to open attachment: %w", err)
+	}
+	return a, body, nil
+}
+
+// signAttachment sets the attachment's download URL, valid for AttachmentURLTTL.
+// Expiry is rounded up to the minute so repeated reads return the same URL (cache friendly).
+func (s *Service) signAttachment(a *Attachment) {
+	expiresAt := time.Now().Add(s.cfg.AttachmentURLTTL).Truncate(time.Minute).Add(time.Minute)
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expiresAt.Unix(), 10)},
+		"signature": {s.downloadSignature(a.ID, expiresAt.Unix())},
+	}
+	a.URL = s.cfg.PublicBaseURL + "/attachments/" + a.ID + "?" + query.Encode()
+	a.URLExpiresAt = expiresAt.UTC()
+}
+
+// downloadSignature is base64url(HMAC-SHA256(jwtSecret, "attachment:<id>:<expires>")).
+// The prefix keeps these signatures from being valid for any other use of the secret.
+func (s *Service) downloadSignature(id string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	fmt.Fprintf(mac, "attachment:%s:%d", id, expiresAt)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// deleteBlob removes a blob whose upload did not complete.
+func (s *Service) deleteBlob(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		s.logger.WarnContext(ctx, "attachment blob not deleted", "key", key, "error", err)
+	}
+}
+
+// uploadError maps a failure reading the request body to an API error.
+func uploadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errAttachmentTooLarge.Wrap(err)
+	}
+	return fmt.Errorf("failed to store attachment: %w", err)
+}
+
+// cleanFilename keeps the base name of the client's filename without control characters.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+	if runes := []rune(name); len(runes) > maxFilenameRunes {
+		name = string(runes[:maxFilenameRunes])
+	}
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,103 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// attachmentColumns is the select list scanned by scanAttachment.
+const attachmentColumns = `id, feedback_id, storage_key, filename, content_type, size_bytes, created_at`
+
+// scanAttachment scans a row selected with attachmentColumns.
+func scanAttachment(row pgx.Row) (*Attachment, error) {
+	var a Attachment
+	var id, feedbackID uuid.UUID
+	if err := row.Scan(&id, &feedbackID, &a.storageKey, &a.Filename, &a.ContentType, &a.SizeBytes, &a.CreatedAt); err != nil {
+		return nil, err
+	}
+	a.ID = id.String()
+	a.FeedbackID = feedbackID.String()
+	return &a, nil
+}
+
+// CreateAttachment records an uploaded blob on the user's feedback. The feedback row is locked
+// while counting, so concurrent uploads cannot exceed maxAttachments.
+func (r *Repository) CreateAttachment(ctx context.Context, id, feedbackID, userID uuid.UUID, storageKey, filename, contentType string, size int64, maxAttachments int) (*Attachment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var count int
+	err = tx.QueryRow(ctx, `
+		SELECT (SELECT count(*) FROM feedback_attachments WHERE feedback_id = f.id)
+		FROM feedback f
+		WHERE f.id = $1 AND f.user_id = $2
+		FOR UPDATE
+	`, feedbackID, userID).Scan(&count)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to lock feedback: %w", err)
+	}
+	if count >= maxAttachments {
+		return nil, errTooManyAttachments.WithDetails(map[string]int{"maxAttachments": maxAttachments})
+	}
+
+	query := `
+		INSERT INTO feedback_attachments (id, feedback_id, storage_key, filename, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + attachmentColumns
+	a, err := scanAttachment(tx.QueryRow(ctx, query, id, feedbackID, storageKey, filename, contentType, size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit attachment: %w", err)
+	}
+	return a, nil
+}
+
+// ListAttachments returns the attachments of a feedback item, oldest first.
+func (r *Repository) ListAttachments(ctx context.Context, feedbackID uuid.UUID) ([]Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM feedback_attachments WHERE feedback_id = $1 ORDER BY created_at ASC, id ASC`
+	rows, err := r.pool.Query(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// GetAttachment returns an attachment by ID regardless of owner; callers authorize first
+// (download URLs are signed).
+func (r *Repository) GetAttachment(ctx context.Context, id uuid.UUID) (*Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM feedback_attachments WHERE id = $1`
+	a, err := scanAttachment(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+	return a, nil
+}
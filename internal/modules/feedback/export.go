@@ -0,0 +1,125 @@
+package feedback
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export formats of GET /admin/feedback/export.
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+)
+
+// exportColumns is the header row of CSV exports.
+var exportColumns = []string{
+	"id", "created_at", "updated_at", "user_id", "user_email", "status",
+	"category", "rating", "platform", "app_version", "os_version", "context",
+	"assignee_id", "assignee_email", "message",
+}
+
+// exportEncoder writes exported feedback to the response body.
+type exportEncoder interface {
+	// ContentType is the response's Content-Type.
+	ContentType() string
+	// Begin writes what precedes the first item, e.g. the CSV header row.
+	Begin() error
+	Encode(a *AdminFeedback) error
+	// End flushes buffered output.
+	End() error
+}
+
+// newExportEncoder returns the encoder for format (csv or ndjson) writing to w.
+func newExportEncoder(format string, w io.Writer) (exportEncoder, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExport{w: csv.NewWriter(w)}, nil
+	case ExportNDJSON:
+		return &ndjsonExport{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, errInvalidExportFormat
+	}
+}
+
+// ExportFeedback calls fn for every feedback item matching filter, oldest first, without
+// loading them all into memory. A search (filter.Query) narrows the export but does not reorder it.
+func (s *Service) ExportFeedback(ctx context.Context, filter AdminFeedbackFilter, fn func(*AdminFeedback) error) error {
+	return s.repo.ExportAll(ctx, filter, fn)
+}
+
+// csvExport writes one row per feedback item with exportColumns; context is a JSON object.
+type csvExport struct {
+	w *csv.Writer
+}
+
+func (e *csvExport) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (e *csvExport) Begin() error { return e.w.Write(exportColumns) }
+
+func (e *csvExport) Encode(a *AdminFeedback) error {
+	var updatedAt, rating, contextJSON string
+	if a.UpdatedAt != nil {
+		updatedAt = a.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	if a.Rating != nil {
+		rating = strconv.Itoa(*a.Rating)
+	}
+	if len(a.Context) > 0 {
+		b, err := json.Marshal(a.Context)
+		if err != nil {
+			return fmt.Errorf("failed to encode context: %w", err)
+		}
+		contextJSON = string(b)
+	}
+
+	return e.w.Write([]string{
+		a.ID,
+		a.CreatedAt.UTC().Format(time.RFC3339),
+		updatedAt,
+		a.UserID,
+		csvSafe(a.UserEmail),
+		a.Status,
+		a.Category,
+		rating,
+		a.Platform,
+		csvSafe(a.AppVersion),
+		csvSafe(a.OSVersion),
+		contextJSON,
+		deref(a.AssigneeID),
+		csvSafe(deref(a.AssigneeEmail)),
+		csvSafe(a.Message),
+	})
+}
+
+func (e *csvExport) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvSafe defuses user-supplied text that a spreadsheet would run as a formula
+// (CSV injection) by prefixing it with a single quote.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ndjsonExport writes one JSON object per line, shaped like the admin list items.
+type ndjsonExport struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonExport) ContentType() string { return "application/x-ndjson" }
+
+func (e *ndjsonExport) Begin() error { return nil }
+
+func (e *ndjsonExport) Encode(a *AdminFeedback) error { return e.enc.Encode(a) }
+
+func (e *ndjsonExport) End() error { return nil }
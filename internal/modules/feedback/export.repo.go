@@ -0,0 +1,76 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// exportBatchSize is how many rows ExportAll fetches from its cursor at a time.
+const exportBatchSize = 500
+
+// ExportAll calls fn for every feedback item matching filter, oldest first. The rows are
+// read through a server-side cursor in batches of exportBatchSize, so memory use does not
+// grow with the export. An error from fn stops the export and is returned as is.
+func (r *Repository) ExportAll(ctx context.Context, filter AdminFeedbackFilter, fn func(*AdminFeedback) error) error {
+	var args queryArgs
+	conds := adminConditions(filter, &args)
+
+	// Cursors only live inside a transaction; a read-only one also sees a single snapshot
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		DECLARE feedback_export NO SCROLL CURSOR FOR
+		SELECT ` + adminFeedbackColumns + `
+		FROM ` + adminFeedbackFrom + `
+		WHERE ` + strings.Join(conds, " AND ") + `
+		ORDER BY f.created_at ASC, f.id ASC
+	`
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to open export cursor: %w", err)
+	}
+
+	fetch := "FETCH FORWARD " + strconv.Itoa(exportBatchSize) + " FROM feedback_export"
+	for {
+		n, err := r.exportBatch(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if n < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// exportBatch fetches one batch from the export cursor, passes each row to fn and returns
+// the number of rows fetched.
+func (r *Repository) exportBatch(ctx context.Context, tx pgx.Tx, fetch string, fn func(*AdminFeedback) error) (int, error) {
+	rows, err := tx.Query(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch export rows: %w", err)
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		a, err := scanAdminFeedback(rows)
+		if err != nil {
+			return n, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		if err := fn(a); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("failed to fetch export rows: %w", err)
+	}
+	return n, nil
+}
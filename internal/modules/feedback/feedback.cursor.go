@@ -0,0 +1,66 @@
+package feedback
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor identifies a position in a (created_at, id) ordered feedback list, or in a
+// (rank, created_at, id) ordered list of search results when Rank is set.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	Rank      *float32
+}
+
+// Encode returns the opaque string form handed to clients.
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	if c.Rank != nil {
+		raw += "|" + strconv.FormatFloat(float64(*c.Rank), 'g', -1, 32)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode.
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Cursor{}, fmt.Errorf("invalid cursor format")
+	}
+	createdAtStr, idStr := parts[0], parts[1]
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor timestamp: %w", err)
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor id: %w", err)
+	}
+
+	c := Cursor{CreatedAt: createdAt, ID: id}
+	if len(parts) == 3 {
+		rank, err := strconv.ParseFloat(parts[2], 32)
+		if err != nil {
+			return Cursor{}, fmt.Errorf("invalid cursor rank: %w", err)
+		}
+		r := float32(rank)
+		c.Rank = &r
+	}
+	return c, nil
+}
+
+func cursorOf(f Feedback) string {
+	return Cursor{CreatedAt: f.CreatedAt, ID: uuid.MustParse(f.ID)}.Encode()
+}
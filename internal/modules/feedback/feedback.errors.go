@@ -0,0 +1,36 @@
+package feedback
+
+import (
+	"net/http"
+
+	"feedback/internal/shared/httpx"
+)
+
+// API errors returned by the feedback module; handlers write them with httpx.WriteErr.
+var (
+	errUnauthorized      = httpx.NewError(http.StatusUnauthorized, "unauthorized", "Authentication is required.")
+	errInvalidUserID     = httpx.NewError(http.StatusUnauthorized, "invalid_user_id", "The access token does not identify a valid user.")
+	errMessageRequired   = httpx.NewError(http.StatusBadRequest, "message_required", "Message is required.")
+	errMessageTooLong    = httpx.NewError(http.StatusBadRequest, "message_too_long", "Message is too long.")
+	errInvalidLimit      = httpx.NewError(http.StatusBadRequest, "invalid_limit", "limit must be a number between 1 and 100.")
+	errInvalidCursor     = httpx.NewError(http.StatusBadRequest, "invalid_cursor", "The pagination cursor is invalid.")
+	errFeedbackNotFound  = httpx.NewError(http.StatusNotFound, "feedback_not_found", "Feedback not found.")
+	errEditWindowExpired = httpx.NewError(http.StatusForbidden, "edit_window_expired", "Feedback can no longer be changed.")
+	errInvalidFilter     = httpx.NewError(http.StatusBadRequest, "invalid_filter", "A filter parameter is invalid.")
+
+	errInvalidExportFormat = httpx.NewError(http.StatusBadRequest, "invalid_export_format", "format must be csv or ndjson.")
+
+	errInvalidTransition = httpx.NewError(http.StatusConflict, "invalid_status_transition", "The feedback cannot move to that status from its current one.")
+	errInvalidAssignee   = httpx.NewError(http.StatusBadRequest, "invalid_assignee", "Feedback can only be assigned to an admin user.")
+
+	errNotMultipart       = httpx.NewError(http.StatusUnsupportedMediaType, "unsupported_media_type", "Attachments must be uploaded as multipart/form-data.")
+	errInvalidMultipart   = httpx.NewError(http.StatusBadRequest, "invalid_multipart", "The multipart body could not be read.")
+	errFileRequired       = httpx.NewError(http.StatusBadRequest, "file_required", "The upload must contain a \"file\" part.")
+	errAttachmentEmpty    = httpx.NewError(http.StatusBadRequest, "attachment_empty", "The uploaded file is empty.")
+	errAttachmentTooLarge = httpx.NewError(http.StatusRequestEntityTooLarge, "attachment_too_large", "The uploaded file is too large.")
+	errAttachmentType     = httpx.NewError(http.StatusUnsupportedMediaType, "unsupported_attachment_type", "Only PNG, JPEG, GIF, WebP, PDF and plain text files can be attached.")
+	errTooManyAttachments = httpx.NewError(http.StatusConflict, "too_many_attachments", "This feedback already has the maximum number of attachments.")
+	errAttachmentNotFound = httpx.NewError(http.StatusNotFound, "attachment_not_found", "Attachment not found.")
+	errInvalidDownloadURL = httpx.NewError(http.StatusForbidden, "invalid_signature", "This download link is invalid.")
+	errDownloadURLExpired = httpx.NewError(http.StatusForbidden, "url_expired", "This download link has expired. Fetch the feedback again for a new one.")
+)
@@ -1,10 +1,14 @@
 package feedback
 
 import (
-	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"feedback/internal/middleware"
 	"feedback/internal/shared/httpx"
@@ -14,62 +18,272 @@ import (
 
 type Handler struct {
 	service *Service
+	logger  *slog.Logger
 }
 
-func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service *Service, logger *slog.Logger) *Handler {
+	return &Handler{service: service, logger: logger}
 }
 
-// HandleCreateFeedback handles POST /feedback
-func (h *Handler) HandleCreateFeedback(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		httpx.WriteError(w, http.StatusMethodNotAllowed, "method_not_allowed")
-		return
+// writeErr writes err as an API error, logging it first when it is unexpected (5xx).
+func (h *Handler) writeErr(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if httpx.IsServerError(err) {
+		h.logger.ErrorContext(r.Context(), msg, "error", err)
 	}
+	httpx.WriteErr(w, err)
+}
 
-	// Extract authenticated user from context (set by middleware)
+// authUser extracts the authenticated user (set by middleware) and writes a 401 if missing.
+func authUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, string, bool) {
 	userIDStr, userEmail, ok := middleware.GetAuthUser(r)
 	if !ok {
 		// Should never happen if middleware is working correctly
-		httpx.WriteError(w, http.StatusUnauthorized, "unauthorized")
-		return
+		httpx.WriteErr(w, errUnauthorized)
+		return uuid.Nil, "", false
 	}
 
 	// Parse user ID as UUID
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		httpx.WriteError(w, http.StatusUnauthorized, "invalid_user_id")
+		httpx.WriteErr(w, errInvalidUserID)
+		return uuid.Nil, "", false
+	}
+
+	return userID, userEmail, true
+}
+
+// feedbackID parses the {id} path value; unknown and malformed IDs are both reported as not found.
+func feedbackID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		httpx.WriteErr(w, errFeedbackNotFound)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// decodeJSON decodes and validates the request body into dst, capped at the configured
+// body size. It writes the error and returns false on failure.
+func (h *Handler) decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := httpx.DecodeJSON(w, r, dst, h.service.cfg.MaxBodyBytes); err != nil {
+		httpx.WriteErr(w, err)
+		return false
+	}
+	return true
+}
+
+// HandleCreateFeedback handles POST /feedback
+func (h *Handler) HandleCreateFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, userEmail, ok := authUser(w, r)
+	if !ok {
 		return
 	}
 
 	// Decode request body
 	var req CreateFeedbackRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		httpx.WriteError(w, http.StatusBadRequest, "invalid_json")
+	if !h.decodeJSON(w, r, &req) {
 		return
 	}
 
 	// Create feedback
-	created, err := h.service.CreateFeedback(r.Context(), userID, userEmail, req.Message)
+	created, err := h.service.CreateFeedback(r.Context(), userID, userEmail, req)
+	if err != nil {
+		h.writeErr(w, r, "create feedback failed", err)
+		return
+	}
+
+	// Return created feedback
+	httpx.WriteJSON(w, http.StatusCreated, created)
+}
+
+// HandleListFeedback handles GET /feedback?limit=&before=&after=
+func (h *Handler) HandleListFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if v := query.Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			httpx.WriteErr(w, errInvalidLimit)
+			return
+		}
+	}
+
+	resp, err := h.service.ListFeedback(r.Context(), userID, limit, query.Get("before"), query.Get("after"))
+	if err != nil {
+		h.writeErr(w, r, "list feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// HandleGetFeedback handles GET /feedback/{id}
+func (h *Handler) HandleGetFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := feedbackID(w, r)
+	if !ok {
+		return
+	}
+
+	detail, err := h.service.GetFeedback(r.Context(), id, userID)
+	if err != nil {
+		h.writeErr(w, r, "get feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, detail)
+}
+
+// HandleUpdateFeedback handles PATCH /feedback/{id}
+func (h *Handler) HandleUpdateFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, userEmail, ok := authUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := feedbackID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateFeedbackRequest
+	if !h.decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.service.UpdateFeedback(r.Context(), id, userID, userEmail, req.Message)
 	if err != nil {
-		// IMPORTANT: log real error so we can debug
-		log.Printf("CreateFeedback failed: %v", err)
+		h.writeErr(w, r, "update feedback failed", err)
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, updated)
+}
 
-		// Check for validation errors
-		if strings.Contains(err.Error(), "message_required") {
-			httpx.WriteError(w, http.StatusBadRequest, "message_required")
+// HandleDeleteFeedback handles DELETE /feedback/{id}
+func (h *Handler) HandleDeleteFeedback(w http.ResponseWriter, r *http.Request) {
+	userID, userEmail, ok := authUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := feedbackID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteFeedback(r.Context(), id, userID, userEmail); err != nil {
+		h.writeErr(w, r, "delete feedback failed", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// multipartOverhead is allowed on top of AttachmentMaxBytes for part headers and boundaries.
+const multipartOverhead = 64 << 10
+
+// uploadTimeout replaces the server's ReadTimeout and WriteTimeout for attachment uploads,
+// which can take longer than 10s to arrive on a slow connection.
+const uploadTimeout = 5 * time.Minute
+
+// HandleUploadAttachment handles POST /feedback/{id}/attachments (multipart/form-data, one "file" part)
+func (h *Handler) HandleUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := authUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := feedbackID(w, r)
+	if !ok {
+		return
+	}
+
+	// The write deadline runs from the end of the request headers too, so the response
+	// would be lost after a slow upload if only the read deadline moved
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(uploadTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(r.Context(), "failed to extend upload read deadline", "error", err)
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.WarnContext(r.Context(), "failed to extend upload write deadline", "error", err)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.cfg.AttachmentMaxBytes+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		httpx.WriteErr(w, errNotMultipart)
+		return
+	}
+
+	// Stream the first "file" part straight to storage; other parts are skipped
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			httpx.WriteErr(w, errFileRequired)
 			return
 		}
-		if strings.Contains(err.Error(), "message_too_long") {
-			httpx.WriteError(w, http.StatusBadRequest, "message_too_long")
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				httpx.WriteErr(w, errAttachmentTooLarge.Wrap(err))
+				return
+			}
+			httpx.WriteErr(w, errInvalidMultipart.Wrap(err))
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := h.service.AddAttachment(r.Context(), id, userID, part.FileName(), part)
+		if err != nil {
+			h.writeErr(w, r, "add attachment failed", err)
 			return
 		}
+		httpx.WriteJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
 
-		// Generic error
-		httpx.WriteError(w, http.StatusInternalServerError, "internal_error")
+// HandleDownloadAttachment handles GET /attachments/{id}?expires=&signature=
+func (h *Handler) HandleDownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		httpx.WriteErr(w, errAttachmentNotFound)
 		return
 	}
 
-	// Return created feedback
-	httpx.WriteJSON(w, http.StatusCreated, created)
+	query := r.URL.Query()
+	attachment, body, err := h.service.OpenAttachment(r.Context(), id, query.Get("expires"), query.Get("signature"))
+	if err != nil {
+		h.writeErr(w, r, "open attachment failed", err)
+		return
+	}
+	defer body.Close()
+
+	// Images display inline; anything else downloads. Never let the browser reinterpret the bytes.
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, body); err != nil {
+		h.logger.WarnContext(r.Context(), "attachment download interrupted", "attachment_id", attachment.ID, "error", err)
+	}
 }
@@ -2,38 +2,320 @@ package feedback
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// feedbackColumns is the select list scanned by scanFeedback.
+const feedbackColumns = `id, user_id, message, created_at, updated_at,
+	category, rating, app_version, platform, os_version, metadata`
+
 type Repository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+}
+
+func NewRepository(pool *pgxpool.Pool, logger *slog.Logger) *Repository {
+	return &Repository{pool: pool, logger: logger}
+}
+
+// scanFeedback scans a row selected with feedbackColumns, followed by any extra columns into extra.
+func scanFeedback(row pgx.Row, extra ...any) (*Feedback, error) {
+	var f Feedback
+	var id, uid uuid.UUID
+	var category, appVersion, platform, osVersion *string
+	dest := []any{&id, &uid, &f.Message, &f.CreatedAt, &f.UpdatedAt,
+		&category, &f.Rating, &appVersion, &platform, &osVersion, &f.Context}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	f.ID = id.String()
+	f.UserID = uid.String()
+	f.Edited = f.UpdatedAt != nil
+	f.Category = deref(category)
+	f.AppVersion = deref(appVersion)
+	f.Platform = deref(platform)
+	f.OSVersion = deref(osVersion)
+	return &f, nil
 }
 
-func NewRepository(pool *pgxpool.Pool) *Repository {
-	return &Repository{pool: pool}
+// deref returns the value of a nullable text column, "" for NULL.
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 // Create inserts a new feedback record and returns it.
-func (r *Repository) Create(ctx context.Context, userID uuid.UUID, message string) (*Feedback, error) {
+// The Slack notification is enqueued in the outbox within the same transaction,
+// so a committed feedback row always has a pending delivery.
+func (r *Repository) Create(ctx context.Context, userID uuid.UUID, userEmail, message string, meta FeedbackMetadata) (*Feedback, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Empty strings are stored as NULL
 	query := `
-		INSERT INTO feedback (user_id, message)
-		VALUES ($1, $2)
-		RETURNING id, user_id, message, created_at
-	`
+		INSERT INTO feedback (user_id, message, category, rating, app_version, platform, os_version, metadata)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8)
+		RETURNING ` + feedbackColumns
 
-	var f Feedback
-	var id, uid uuid.UUID
-	err := r.pool.QueryRow(ctx, query, userID, message).Scan(
-		&id, &uid, &f.Message, &f.CreatedAt,
-	)
+	metadata := meta.Context
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	f, err := scanFeedback(tx.QueryRow(ctx, query, userID, message,
+		meta.Category, meta.Rating, meta.AppVersion, meta.Platform, meta.OSVersion, metadata))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create feedback: %w", err)
 	}
 
-	f.ID = id.String()
-	f.UserID = uid.String()
-	return &f, nil
+	event := FeedbackEvent{
+		Type:             EventFeedbackCreated,
+		FeedbackID:       f.ID,
+		UserEmail:        userEmail,
+		Message:          f.Message,
+		OccurredAt:       f.CreatedAt,
+		FeedbackMetadata: f.FeedbackMetadata,
+	}
+	if err := enqueueSlackEvent(ctx, tx, event, true); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit feedback: %w", err)
+	}
+	r.logger.DebugContext(ctx, "slack event enqueued", "feedback_id", f.ID, "event", event.Type)
+
+	return f, nil
+}
+
+// GetByID returns the feedback with the given ID if it belongs to userID.
+func (r *Repository) GetByID(ctx context.Context, id, userID uuid.UUID) (*Feedback, error) {
+	query := `SELECT ` + feedbackColumns + ` FROM feedback WHERE id = $1 AND user_id = $2`
+	f, err := scanFeedback(r.pool.QueryRow(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	return f, nil
+}
+
+// ListEdits returns the edit history of a feedback item, oldest first.
+func (r *Repository) ListEdits(ctx context.Context, feedbackID uuid.UUID) ([]FeedbackEdit, error) {
+	query := `
+		SELECT previous_message, edited_at
+		FROM feedback_edits
+		WHERE feedback_id = $1
+		ORDER BY edited_at ASC
+	`
+	rows, err := r.pool.Query(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback edits: %w", err)
+	}
+	defer rows.Close()
+
+	edits := []FeedbackEdit{}
+	for rows.Next() {
+		var e FeedbackEdit
+		if err := rows.Scan(&e.PreviousMessage, &e.EditedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback edit: %w", err)
+		}
+		edits = append(edits, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feedback edits: %w", err)
+	}
+	return edits, nil
+}
+
+// UpdateMessage replaces the message of the user's feedback if it was created after editableSince.
+// The previous message is kept in feedback_edits and a Slack update is enqueued in the same transaction.
+// Saving an unchanged message is a no-op.
+func (r *Repository) UpdateMessage(ctx context.Context, id, userID uuid.UUID, userEmail, message string, editableSince time.Time) (*Feedback, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := scanFeedback(tx.QueryRow(ctx,
+		`SELECT `+feedbackColumns+` FROM feedback WHERE id = $1 AND user_id = $2 FOR UPDATE`, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to lock feedback: %w", err)
+	}
+
+	if current.CreatedAt.Before(editableSince) {
+		return nil, errEditWindowExpired
+	}
+	if current.Message == message {
+		return current, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO feedback_edits (feedback_id, previous_message) VALUES ($1, $2)`, id, current.Message); err != nil {
+		return nil, fmt.Errorf("failed to record feedback edit: %w", err)
+	}
+
+	query := `
+		UPDATE feedback
+		SET message = $2, updated_at = now()
+		WHERE id = $1
+		RETURNING ` + feedbackColumns
+
+	updated, err := scanFeedback(tx.QueryRow(ctx, query, id, message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update feedback: %w", err)
+	}
+
+	event := FeedbackEvent{
+		Type:             EventFeedbackUpdated,
+		FeedbackID:       updated.ID,
+		UserEmail:        userEmail,
+		Message:          updated.Message,
+		PreviousMessage:  current.Message,
+		OccurredAt:       *updated.UpdatedAt,
+		FeedbackMetadata: updated.FeedbackMetadata,
+	}
+	if err := enqueueSlackEvent(ctx, tx, event, true); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit feedback update: %w", err)
+	}
+	r.logger.DebugContext(ctx, "slack event enqueued", "feedback_id", updated.ID, "event", event.Type)
+
+	return updated, nil
+}
+
+// Delete removes the user's feedback (and its edit history and attachment rows) and enqueues
+// a Slack deletion notice. It returns the storage keys of the deleted attachments.
+func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID, userEmail string) ([]string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT a.storage_key
+		FROM feedback_attachments a
+		JOIN feedback f ON f.id = a.feedback_id
+		WHERE a.feedback_id = $1 AND f.user_id = $2
+	`, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	storageKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	var message string
+	err = tx.QueryRow(ctx,
+		`DELETE FROM feedback WHERE id = $1 AND user_id = $2 RETURNING message`, id, userID).Scan(&message)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to delete feedback: %w", err)
+	}
+
+	// The row is gone, so the outbox entry carries the ID in its payload only.
+	event := FeedbackEvent{
+		Type:       EventFeedbackDeleted,
+		FeedbackID: id.String(),
+		UserEmail:  userEmail,
+		Message:    message,
+		OccurredAt: time.Now(),
+	}
+	if err := enqueueSlackEvent(ctx, tx, event, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit feedback delete: %w", err)
+	}
+	r.logger.DebugContext(ctx, "slack event enqueued", "feedback_id", id, "event", event.Type)
+
+	return storageKeys, nil
+}
+
+// ListByUser returns up to limit of the user's feedback, newest first.
+// With before set it returns entries older than the cursor; with after set, entries newer
+// than the cursor (still newest first). Ordering on (created_at, id) keeps pages stable
+// when several entries share a timestamp.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID, limit int, before, after *Cursor) ([]Feedback, error) {
+	query := `
+		SELECT ` + feedbackColumns + `
+		FROM feedback
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	args := []any{userID, limit}
+
+	switch {
+	case before != nil:
+		query = `
+			SELECT ` + feedbackColumns + `
+			FROM feedback
+			WHERE user_id = $1
+			  AND (created_at, id) < ($3, $4)
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		`
+		args = append(args, before.CreatedAt, before.ID)
+	case after != nil:
+		// Walk forward from the cursor, then flip to newest first below.
+		query = `
+			SELECT ` + feedbackColumns + `
+			FROM feedback
+			WHERE user_id = $1
+			  AND (created_at, id) > ($3, $4)
+			ORDER BY created_at ASC, id ASC
+			LIMIT $2
+		`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Feedback{}
+	for rows.Next() {
+		f, err := scanFeedback(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		items = append(items, *f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
+	}
+
+	if after != nil {
+		slices.Reverse(items)
+	}
+
+	return items, nil
 }
@@ -1,20 +1,72 @@
 package feedback
 
 import (
+	"log/slog"
 	"net/http"
 
+	"feedback/internal/mail"
 	"feedback/internal/middleware"
+	"feedback/internal/shared/httpx"
+	"feedback/internal/storage"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // RegisterRoutes registers all feedback routes on the provided mux.
-func RegisterRoutes(mux *http.ServeMux, pool *pgxpool.Pool, jwtSecret string) {
-	repo := NewRepository(pool)
-	slackClient := NewMockSlackClient()
-	service := NewService(repo, slackClient)
-	handler := NewHandler(service)
+// Slack delivery is handled separately by the outbox Dispatcher.
+// Attachments are stored in store; reply notifications are sent with mailer.
+func RegisterRoutes(mux *http.ServeMux, pool *pgxpool.Pool, jwtSecret string, sessions *middleware.SessionCache, store storage.Store, mailer mail.Mailer, templates *mail.Templates, cfg Config, logger *slog.Logger) {
+	logger = logger.With("module", "feedback")
+
+	repo := NewRepository(pool, logger)
+	service := NewService(repo, store, mailer, templates, jwtSecret, cfg, logger)
+	handler := NewHandler(service, logger)
+
+	requireAuth := middleware.RequireAuth(jwtSecret, sessions, logger)
+	requireAdmin := func(next http.HandlerFunc) http.HandlerFunc {
+		return requireAuth(middleware.RequireRole(middleware.RoleAdmin)(next))
+	}
 
 	// POST /feedback - requires authentication
-	mux.HandleFunc("/feedback", middleware.RequireAuth(jwtSecret)(handler.HandleCreateFeedback))
+	mux.HandleFunc("POST /feedback", requireAuth(handler.HandleCreateFeedback))
+	// GET /feedback - list own feedback, requires authentication
+	mux.HandleFunc("GET /feedback", requireAuth(handler.HandleListFeedback))
+	// Any other method on /feedback
+	mux.HandleFunc("/feedback", httpx.MethodNotAllowed)
+
+	// /feedback/{id} - single item, owner only
+	mux.HandleFunc("GET /feedback/{id}", requireAuth(handler.HandleGetFeedback))
+	mux.HandleFunc("PATCH /feedback/{id}", requireAuth(handler.HandleUpdateFeedback))
+	mux.HandleFunc("DELETE /feedback/{id}", requireAuth(handler.HandleDeleteFeedback))
+	mux.HandleFunc("/feedback/{id}", httpx.MethodNotAllowed)
+
+	// POST /feedback/{id}/attachments - multipart upload, owner only
+	mux.HandleFunc("POST /feedback/{id}/attachments", requireAuth(handler.HandleUploadAttachment))
+	mux.HandleFunc("/feedback/{id}/attachments", httpx.MethodNotAllowed)
+
+	// GET /attachments/{id} - download; authorized by the URL signature, not a JWT
+	mux.HandleFunc("GET /attachments/{id}", handler.HandleDownloadAttachment)
+	mux.HandleFunc("/attachments/{id}", httpx.MethodNotAllowed)
+
+	// /admin/feedback - every user's feedback, admin role only
+	mux.HandleFunc("GET /admin/feedback", requireAdmin(handler.HandleAdminListFeedback))
+	mux.HandleFunc("/admin/feedback", httpx.MethodNotAllowed)
+	// GET /admin/feedback/export - the same filters, streamed as CSV or NDJSON. Other methods
+	// fall through to /admin/feedback/{id} below (a catch-all here would conflict with it).
+	mux.HandleFunc("GET /admin/feedback/export", requireAdmin(handler.HandleAdminExportFeedback))
+	mux.HandleFunc("GET /admin/feedback/{id}", requireAdmin(handler.HandleAdminGetFeedback))
+	mux.HandleFunc("/admin/feedback/{id}", httpx.MethodNotAllowed)
+
+	// Triage: status, assignee and internal notes, admin role only
+	mux.HandleFunc("PUT /admin/feedback/{id}/status", requireAdmin(handler.HandleAdminSetStatus))
+	mux.HandleFunc("/admin/feedback/{id}/status", httpx.MethodNotAllowed)
+	mux.HandleFunc("PUT /admin/feedback/{id}/assignee", requireAdmin(handler.HandleAdminAssign))
+	mux.HandleFunc("DELETE /admin/feedback/{id}/assignee", requireAdmin(handler.HandleAdminUnassign))
+	mux.HandleFunc("/admin/feedback/{id}/assignee", httpx.MethodNotAllowed)
+	mux.HandleFunc("POST /admin/feedback/{id}/notes", requireAdmin(handler.HandleAdminAddNote))
+	mux.HandleFunc("/admin/feedback/{id}/notes", httpx.MethodNotAllowed)
+
+	// POST /admin/feedback/{id}/replies - public reply, emailed to the submitter
+	mux.HandleFunc("POST /admin/feedback/{id}/replies", requireAdmin(handler.HandleAdminReply))
+	mux.HandleFunc("/admin/feedback/{id}/replies", httpx.MethodNotAllowed)
 }
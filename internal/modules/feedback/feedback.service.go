@@ -3,42 +3,329 @@ package feedback
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"feedback/internal/mail"
+	"feedback/internal/shared/httpx"
+	"feedback/internal/storage"
 
 	"github.com/google/uuid"
 )
 
+// MaxMessageRunesLimit is the hard ceiling enforced by the feedback_message_length
+// CHECK constraint (005_feedback_message_length.sql). MaxMessageRunes may only lower it.
+const MaxMessageRunesLimit = 4000
+
+// Limits on the free-form context map of FeedbackMetadata (its entry count is capped by the
+// validate tag on CreateFeedbackRequest).
+const (
+	maxContextKeyRunes   = 64
+	maxContextValueRunes = 256
+)
+
+// Config holds the feedback module's tunables.
+type Config struct {
+	EditWindow      time.Duration // FEEDBACK_EDIT_WINDOW, how long after creation feedback may be edited
+	MaxMessageRunes int           // FEEDBACK_MAX_MESSAGE_RUNES, max message length in characters
+	MaxBodyBytes    int64         // FEEDBACK_MAX_BODY_BYTES, max JSON request body size
+
+	AttachmentMaxBytes int64         // ATTACHMENT_MAX_BYTES, max size of one uploaded file
+	MaxAttachments     int           // ATTACHMENT_MAX_PER_FEEDBACK
+	AttachmentURLTTL   time.Duration // ATTACHMENT_URL_TTL, lifetime of signed download URLs
+	PublicBaseURL      string        // PUBLIC_BASE_URL, prefixed to download URLs; empty gives relative URLs
+
+	DeeplinkURL string // APP_DEEPLINK_URL, linked from reply emails with ?feedback_id=<id>
+}
+
+func (c Config) withDefaults() Config {
+	if c.EditWindow <= 0 {
+		c.EditWindow = 15 * time.Minute
+	}
+	if c.MaxMessageRunes <= 0 {
+		c.MaxMessageRunes = MaxMessageRunesLimit
+	}
+	if c.MaxMessageRunes > MaxMessageRunesLimit {
+		c.MaxMessageRunes = MaxMessageRunesLimit
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = 64 << 10
+	}
+	if c.AttachmentMaxBytes <= 0 {
+		c.AttachmentMaxBytes = 5 << 20
+	}
+	if c.MaxAttachments <= 0 {
+		c.MaxAttachments = 5
+	}
+	if c.AttachmentURLTTL <= 0 {
+		c.AttachmentURLTTL = 15 * time.Minute
+	}
+	c.PublicBaseURL = strings.TrimRight(c.PublicBaseURL, "/")
+	return c
+}
+
 type Service struct {
-	repo        *Repository
-	slackClient SlackClient
+	repo      *Repository
+	store     storage.Store
+	mailer    mail.Mailer
+	templates *mail.Templates
+	jwtSecret string
+	cfg       Config
+	logger    *slog.Logger
 }
 
-func NewService(repo *Repository, slackClient SlackClient) *Service {
+// NewService creates the feedback service. Attachments are kept in store and their
+// download URLs are signed with a key derived from jwtSecret. Reply notifications are
+// rendered from templates and sent with mailer.
+func NewService(repo *Repository, store storage.Store, mailer mail.Mailer, templates *mail.Templates, jwtSecret string, cfg Config, logger *slog.Logger) *Service {
+	if cfg.MaxMessageRunes > MaxMessageRunesLimit {
+		logger.Warn("FEEDBACK_MAX_MESSAGE_RUNES exceeds the database limit", "configured", cfg.MaxMessageRunes, "using", MaxMessageRunesLimit)
+	}
 	return &Service{
-		repo:        repo,
-		slackClient: slackClient,
+		repo:      repo,
+		store:     store,
+		mailer:    mailer,
+		templates: templates,
+		jwtSecret: jwtSecret,
+		cfg:       cfg.withDefaults(),
+		logger:    logger,
 	}
 }
 
-func (s *Service) CreateFeedback(ctx context.Context, userID uuid.UUID, userEmail, message string) (*Feedback, error) {
+// normalizeMessage trims the message and checks it against the configured length in runes.
+func (s *Service) normalizeMessage(message string) (string, error) {
 	normalizedMessage := strings.TrimSpace(message)
 
 	if normalizedMessage == "" {
-		return nil, fmt.Errorf("message_required")
+		return "", errMessageRequired
 	}
+	if utf8.RuneCountInString(normalizedMessage) > s.cfg.MaxMessageRunes {
+		return "", errMessageTooLong.WithDetails(map[string]int{"maxRunes": s.cfg.MaxMessageRunes})
+	}
+
+	return normalizedMessage, nil
+}
 
-	// Persist feedback (DB is source of truth)
-	feedback, err := s.repo.Create(ctx, userID, normalizedMessage)
+// normalizeMetadata checks category, rating and platform (HTTP callers are already held to
+// the same rules by the validate tags), trims the version strings and checks the context entries.
+func normalizeMetadata(meta FeedbackMetadata) (FeedbackMetadata, error) {
+	switch meta.Category {
+	case "", CategoryBug, CategoryIdea, CategoryPraise:
+	default:
+		return meta, fieldError("category", "invalid_value", "must be one of: bug, idea, praise")
+	}
+	if meta.Rating != nil && *meta.Rating < 1 {
+		return meta, fieldError("rating", "too_small", "must be at least 1")
+	}
+	if meta.Rating != nil && *meta.Rating > 5 {
+		return meta, fieldError("rating", "too_large", "must be at most 5")
+	}
+	switch meta.Platform {
+	case "", PlatformIOS, PlatformAndroid, PlatformWeb:
+	default:
+		return meta, fieldError("platform", "invalid_value", "must be one of: ios, android, web")
+	}
+
+	meta.AppVersion = strings.TrimSpace(meta.AppVersion)
+	meta.OSVersion = strings.TrimSpace(meta.OSVersion)
+
+	for key, value := range meta.Context {
+		switch {
+		case strings.TrimSpace(key) == "" || utf8.RuneCountInString(key) > maxContextKeyRunes:
+			return meta, fieldError("context", "invalid_key", fmt.Sprintf("keys must be 1-%d characters", maxContextKeyRunes))
+		case utf8.RuneCountInString(value) > maxContextValueRunes:
+			return meta, fieldError("context", "too_long", fmt.Sprintf("value of %q must be at most %d characters", key, maxContextValueRunes))
+		}
+	}
+
+	return meta, nil
+}
+
+// fieldError reports one invalid request field in the validation_failed format.
+func fieldError(field, code, message string) error {
+	return httpx.ErrValidation.WithDetails(httpx.ValidationDetails{
+		Fields: []httpx.FieldError{{Field: field, Code: code, Message: message}},
+	})
+}
+
+// CreateFeedback validates the message and metadata, then stores the feedback.
+func (s *Service) CreateFeedback(ctx context.Context, userID uuid.UUID, userEmail string, req CreateFeedbackRequest) (*Feedback, error) {
+	normalizedMessage, err := s.normalizeMessage(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := normalizeMetadata(req.FeedbackMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist feedback and enqueue the Slack notification atomically (DB is source of truth).
+	// Delivery happens asynchronously in the outbox Dispatcher.
+	feedback, err := s.repo.Create(ctx, userID, userEmail, normalizedMessage, meta)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create feedback: %w", err)
 	}
 
-	// Publish to Slack (best effort - don't fail request if this fails)
-	if err := s.slackClient.PublishFeedback(ctx, userEmail, normalizedMessage); err != nil {
-		log.Printf("Slack publish failed for feedback %s: %v", feedback.ID, err)
-		// Continue - feedback was stored successfully
+	s.logger.InfoContext(ctx, "feedback created", "feedback_id", feedback.ID, "category", feedback.Category)
+	return feedback, nil
+}
+
+// Page size bounds for ListFeedback.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListFeedback returns one page of the user's feedback, newest first.
+// before and after are opaque cursors from a previous response; at most one may be set.
+func (s *Service) ListFeedback(ctx context.Context, userID uuid.UUID, limit int, before, after string) (*ListFeedbackResponse, error) {
+	limit, beforeCursor, afterCursor, err := parsePage(limit, before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to learn whether another page exists in the walking direction.
+	items, err := s.repo.ListByUser(ctx, userID, limit+1, beforeCursor, afterCursor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feedback: %w", err)
 	}
 
+	resp := &ListFeedbackResponse{}
+	resp.Items, resp.NextCursor, resp.PrevCursor = pageOf(items, limit, beforeCursor, afterCursor, cursorOf)
+	return resp, nil
+}
+
+// parsePage checks a list request's limit (0 means DefaultListLimit) and decodes its
+// before/after cursors, at most one of which may be set.
+func parsePage(limit int, before, after string) (int, *Cursor, *Cursor, error) {
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+	if limit < 1 || limit > MaxListLimit {
+		return 0, nil, nil, errInvalidLimit
+	}
+	if before != "" && after != "" {
+		return 0, nil, nil, errInvalidCursor.WithDetails(map[string]string{"reason": "before and after are mutually exclusive"})
+	}
+
+	var beforeCursor, afterCursor *Cursor
+	if before != "" {
+		c, err := DecodeCursor(before)
+		if err != nil {
+			return 0, nil, nil, errInvalidCursor.Wrap(err)
+		}
+		beforeCursor = &c
+	}
+	if after != "" {
+		c, err := DecodeCursor(after)
+		if err != nil {
+			return 0, nil, nil, errInvalidCursor.Wrap(err)
+		}
+		afterCursor = &c
+	}
+	return limit, beforeCursor, afterCursor, nil
+}
+
+// pageOf trims the limit+1 rows fetched for a page (newest first) to limit and returns
+// them with the cursors of the next (older) and previous (newer) pages, nil when there is none.
+func pageOf[T any](items []T, limit int, before, after *Cursor, cursor func(T) string) ([]T, *string, *string) {
+	hasMore := len(items) > limit
+	if hasMore {
+		if after != nil {
+			// Walking towards newer entries: the extra row is the newest one.
+			items = items[1:]
+		} else {
+			items = items[:limit]
+		}
+	}
+	if len(items) == 0 {
+		return items, nil, nil
+	}
+
+	// Walking backwards, hasMore means older entries remain; walking forwards it means newer ones do.
+	// The cursor we came from always lies on the other side.
+	olderExist := after != nil || hasMore
+	newerExist := before != nil || (after != nil && hasMore)
+
+	var next, prev *string
+	if olderExist {
+		c := cursor(items[len(items)-1])
+		next = &c
+	}
+	if newerExist {
+		c := cursor(items[0])
+		prev = &c
+	}
+	return items, next, prev
+}
+
+// GetFeedback returns the user's feedback item together with its edit history, attachments
+// and the team's replies.
+func (s *Service) GetFeedback(ctx context.Context, id, userID uuid.UUID) (*FeedbackDetail, error) {
+	f, err := s.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	edits, err := s.repo.ListEdits(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback edits: %w", err)
+	}
+
+	attachments, err := s.repo.ListAttachments(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback attachments: %w", err)
+	}
+	for i := range attachments {
+		s.signAttachment(&attachments[i])
+	}
+
+	replies, err := s.repo.ListReplies(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback replies: %w", err)
+	}
+	// The submitter sees replies as coming from the team, not from a named admin
+	for i := range replies {
+		replies[i].AuthorID = nil
+		replies[i].AuthorEmail = nil
+	}
+
+	return &FeedbackDetail{Feedback: *f, Edits: edits, Attachments: attachments, Replies: replies}, nil
+}
+
+// UpdateFeedback edits the message of the user's feedback within the edit window.
+// The previous text is kept in the edit history and Slack is notified of the change.
+func (s *Service) UpdateFeedback(ctx context.Context, id, userID uuid.UUID, userEmail, message string) (*Feedback, error) {
+	normalizedMessage, err := s.normalizeMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	editableSince := time.Now().Add(-s.cfg.EditWindow)
+	feedback, err := s.repo.UpdateMessage(ctx, id, userID, userEmail, normalizedMessage, editableSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update feedback: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "feedback updated", "feedback_id", feedback.ID)
 	return feedback, nil
 }
+
+// DeleteFeedback deletes the user's feedback and notifies Slack.
+// Attachment blobs are removed after the rows; a failure only leaves an orphaned blob behind.
+func (s *Service) DeleteFeedback(ctx context.Context, id, userID uuid.UUID, userEmail string) error {
+	storageKeys, err := s.repo.Delete(ctx, id, userID, userEmail)
+	if err != nil {
+		return fmt.Errorf("failed to delete feedback: %w", err)
+	}
+	s.logger.InfoContext(ctx, "feedback deleted", "feedback_id", id)
+
+	for _, key := range storageKeys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.logger.WarnContext(ctx, "attachment blob not deleted", "feedback_id", id, "key", key, "error", err)
+		}
+	}
+	return nil
+}
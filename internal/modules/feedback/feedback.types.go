@@ -1,9 +1,21 @@
 package feedback
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message limits are also checked against FEEDBACK_MAX_MESSAGE_RUNES by the service;
+// max=4000 is the database ceiling (MaxMessageRunesLimit).
 
 type CreateFeedbackRequest struct {
-	Message string `json:"message"`
+	Message string `json:"message" validate:"required,max=4000"`
+	FeedbackMetadata
+}
+
+type UpdateFeedbackRequest struct {
+	Message string `json:"message" validate:"required,max=4000"`
 }
 
 type CreateFeedbackResponse struct {
@@ -13,9 +25,194 @@ type CreateFeedbackResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// Feedback categories.
+const (
+	CategoryBug    = "bug"
+	CategoryIdea   = "idea"
+	CategoryPraise = "praise"
+)
+
+// Client platforms.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// Feedback statuses (feedback.status). New feedback starts as StatusNew.
+const (
+	StatusNew        = "new"
+	StatusTriaged    = "triaged"
+	StatusInProgress = "in_progress"
+	StatusResolved   = "resolved"
+	StatusWontFix    = "wont_fix"
+)
+
+// FeedbackMetadata is optional context the app attaches when creating feedback.
+// Empty fields are omitted from responses; Context is stored in the metadata JSONB column.
+type FeedbackMetadata struct {
+	Category   string            `json:"category,omitempty" validate:"oneof=bug idea praise"`
+	Rating     *int              `json:"rating,omitempty" validate:"min=1,max=5"`
+	AppVersion string            `json:"app_version,omitempty" validate:"max=32"`
+	Platform   string            `json:"platform,omitempty" validate:"oneof=ios android web"`
+	OSVersion  string            `json:"os_version,omitempty" validate:"max=32"`
+	Context    map[string]string `json:"context,omitempty" validate:"max=20"` // free-form, e.g. {"screen": "settings"}
+}
+
 type Feedback struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Message   string    `json:"message"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	FeedbackMetadata
+}
+
+// FeedbackEdit is a previous version of an edited feedback message.
+type FeedbackEdit struct {
+	PreviousMessage string    `json:"previous_message"`
+	EditedAt        time.Time `json:"edited_at"`
+}
+
+// FeedbackDetail is a single feedback item with its edit history, attachments and the
+// team's replies (all oldest first).
+type FeedbackDetail struct {
+	Feedback
+	Edits       []FeedbackEdit  `json:"edits"`
+	Attachments []Attachment    `json:"attachments"`
+	Replies     []FeedbackReply `json:"replies"`
+}
+
+// FeedbackReply is a public reply from the team to the submitter. The author is only
+// included for admins; the submitter sees replies as coming from the team.
+type FeedbackReply struct {
+	ID          string    `json:"id"`
+	FeedbackID  string    `json:"feedback_id"`
+	AuthorID    *string   `json:"author_id,omitempty"`
+	AuthorEmail *string   `json:"author_email,omitempty"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Attachment is a file uploaded to a feedback item. URL is a signed download link
+// that stops working at URLExpiresAt; fetch the feedback again for a fresh one.
+type Attachment struct {
+	ID           string    `json:"id"`
+	FeedbackID   string    `json:"feedback_id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	CreatedAt    time.Time `json:"created_at"`
+	URL          string    `json:"url"`
+	URLExpiresAt time.Time `json:"url_expires_at"`
+
+	storageKey string
+}
+
+// ListFeedbackResponse is one page of feedback, newest first.
+// Pass NextCursor as ?before= for older entries and PrevCursor as ?after= for newer ones;
+// a nil cursor means there is nothing further in that direction.
+type ListFeedbackResponse struct {
+	Items      []Feedback `json:"items"`
+	NextCursor *string    `json:"next_cursor"`
+	PrevCursor *string    `json:"prev_cursor"`
+}
+
+// AdminFeedback is a feedback item as listed in the admin inbox: any user's, with the
+// submitter's email, the status and the assigned admin. Search results (?q=) also carry
+// Snippet, an HTML-escaped excerpt of the message with the matches wrapped in <mark>.
+type AdminFeedback struct {
+	Feedback
+	UserEmail     string  `json:"user_email"`
+	Status        string  `json:"status"`
+	AssigneeID    *string `json:"assignee_id"`
+	AssigneeEmail *string `json:"assignee_email"`
+	Snippet       string  `json:"snippet,omitempty"`
+
+	rank *float32 // search relevance, for the cursor
+}
+
+// AdminFeedbackDetail is a single feedback item in the admin inbox with its edit history,
+// attachments, replies, internal notes and audit trail (all oldest first).
+type AdminFeedbackDetail struct {
+	AdminFeedback
+	Edits       []FeedbackEdit  `json:"edits"`
+	Attachments []Attachment    `json:"attachments"`
+	Replies     []FeedbackReply `json:"replies"`
+	Notes       []FeedbackNote  `json:"notes"`
+	History     []AuditEntry    `json:"history"`
+}
+
+// FeedbackNote is an internal note on feedback; only admins ever see notes.
+// The author is nil once their account is deleted.
+type FeedbackNote struct {
+	ID          string    `json:"id"`
+	FeedbackID  string    `json:"feedback_id"`
+	AuthorID    *string   `json:"author_id"`
+	AuthorEmail *string   `json:"author_email"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Audit trail actions (feedback_audit.action).
+const (
+	AuditStatusChanged = "status_changed"
+	AuditAssigned      = "assigned"
+	AuditUnassigned    = "unassigned"
+	AuditNoteAdded     = "note_added"
+	AuditReplyAdded    = "reply_added"
+)
+
+// AuditEntry records one triage action. From and To hold statuses for status_changed,
+// user IDs for assigned/unassigned and the note or reply ID (To) for note_added and reply_added.
+type AuditEntry struct {
+	Action     string    `json:"action"`
+	ActorID    *string   `json:"actor_id"`
+	ActorEmail *string   `json:"actor_email"`
+	From       *string   `json:"from"`
+	To         *string   `json:"to"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UpdateStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=new triaged in_progress resolved wont_fix"`
+}
+
+type AssignFeedbackRequest struct {
+	AssigneeID string `json:"assignee_id" validate:"required"`
+}
+
+type CreateNoteRequest struct {
+	Body string `json:"body" validate:"required,max=4000"`
+}
+
+type CreateReplyRequest struct {
+	Body string `json:"body" validate:"required,max=4000"`
+}
+
+// CreateReplyResponse is the new reply and whether the submitter was emailed about it.
+type CreateReplyResponse struct {
+	FeedbackReply
+	EmailSent bool `json:"email_sent"`
+}
+
+// AdminListFeedbackResponse is one page of the admin inbox, newest first; cursors work as in ListFeedbackResponse.
+type AdminListFeedbackResponse struct {
+	Items      []AdminFeedback `json:"items"`
+	NextCursor *string         `json:"next_cursor"`
+	PrevCursor *string         `json:"prev_cursor"`
+}
+
+// AdminFeedbackFilter narrows the admin inbox. Zero fields do not filter.
+type AdminFeedbackFilter struct {
+	Query      string // full-text search (websearch syntax) with a trigram fallback for typos; orders by relevance
+	UserID     *uuid.UUID
+	UserEmail  string     // exact, case-insensitive
+	From       *time.Time // created_at >= From
+	To         *time.Time // created_at < To
+	Category   string
+	Status     string
+	AssigneeID *uuid.UUID
+	Unassigned bool // only feedback without an assignee; wins over AssigneeID
 }
@@ -0,0 +1,229 @@
+package feedback
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"feedback/internal/logging"
+	"feedback/internal/metrics"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DispatcherConfig tunes outbox delivery. Zero values fall back to the defaults below.
+type DispatcherConfig struct {
+	PollInterval time.Duration // how often to look for due entries
+	BatchSize    int           // entries claimed per query
+	MaxAttempts  int           // attempts before an entry is dead-lettered
+	BaseBackoff  time.Duration // delay after the first failure, doubled per attempt
+	MaxBackoff   time.Duration // upper bound for the retry delay
+}
+
+func (c DispatcherConfig) withDefaults() DispatcherConfig {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 5 * time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 20
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 10 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Hour
+	}
+	return c
+}
+
+const (
+	// outboxSendTimeout bounds a single delivery, including Slack 429 waits.
+	outboxSendTimeout = 2 * time.Minute
+	// outboxLease must outlive outboxSendTimeout so a slow send is never claimed twice.
+	outboxLease = outboxSendTimeout + 30*time.Second
+)
+
+// Dispatcher delivers pending slack_outbox entries in a background goroutine.
+type Dispatcher struct {
+	repo   *Repository
+	slack  SlackClient
+	cfg    DispatcherConfig
+	logger *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewDispatcher creates a dispatcher; call Start to begin delivering.
+func NewDispatcher(pool *pgxpool.Pool, slackClient SlackClient, cfg DispatcherConfig, logger *slog.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	logger = logger.With("module", "feedback", "component", "outbox")
+	return &Dispatcher{
+		repo:   NewRepository(pool, logger),
+		slack:  slackClient,
+		cfg:    cfg.withDefaults(),
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start launches the polling loop.
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// Shutdown stops polling, finishes in-flight deliveries and flushes entries that are
+// already due. If ctx expires first, remaining work is cancelled and left for the next start.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.once.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	defer d.cancel()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchDue()
+
+		select {
+		case <-d.stop:
+			// Final pass: deliver what requests committed while the server was draining.
+			d.dispatchDue()
+			d.logger.Info("slack outbox dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchDue delivers due entries batch by batch until none are left.
+func (d *Dispatcher) dispatchDue() {
+	for d.ctx.Err() == nil {
+		entries, err := d.repo.ClaimOutbox(d.ctx, d.cfg.BatchSize, outboxLease)
+		if err != nil {
+			if d.ctx.Err() == nil {
+				d.logger.Error("slack outbox claim failed", "error", err)
+			}
+			return
+		}
+
+		for i, e := range entries {
+			if d.ctx.Err() != nil {
+				// Shutdown ran out of time: hand back what was claimed but never sent
+				d.release(entries[i:])
+				return
+			}
+			d.deliver(e)
+		}
+
+		if len(entries) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver publishes one entry and records the outcome. Log records carry the request ID
+// of the request that enqueued the entry.
+func (d *Dispatcher) deliver(e outboxEntry) {
+	logCtx := context.Background()
+	if e.Event.RequestID != "" {
+		logCtx = logging.WithRequestID(logCtx, e.Event.RequestID)
+	}
+	logger := d.logger.With("outbox_id", e.ID, "feedback_id", e.Event.FeedbackID, "attempt", e.Attempts)
+
+	sendCtx, cancel := context.WithTimeout(d.ctx, outboxSendTimeout)
+	err := d.slack.PublishFeedback(sendCtx, e.Event)
+	cancel()
+
+	// Record the outcome even if shutdown cancelled the send mid-flight.
+	recordCtx, cancelRecord := context.WithTimeout(logCtx, 5*time.Second)
+	defer cancelRecord()
+
+	if err == nil {
+		metrics.SlackPublishes.Inc(metrics.OutcomeSuccess)
+		if err := d.repo.MarkOutboxSent(recordCtx, e.ID); err != nil {
+			logger.ErrorContext(logCtx, "slack outbox delivered but not marked sent", "error", err)
+			return
+		}
+		logger.DebugContext(logCtx, "slack outbox delivered")
+		return
+	}
+
+	if d.ctx.Err() != nil {
+		// Cancelled by shutdown, not a Slack failure: do not count it against the entry
+		logger.WarnContext(logCtx, "slack outbox send cancelled by shutdown", "error", err)
+		d.release([]outboxEntry{e})
+		return
+	}
+
+	if e.Attempts >= d.cfg.MaxAttempts {
+		metrics.SlackPublishes.Inc(metrics.OutcomeDead)
+		logger.ErrorContext(logCtx, "slack outbox dead-lettered", "error", err)
+		if err := d.repo.MarkOutboxDead(recordCtx, e.ID, err.Error()); err != nil {
+			logger.ErrorContext(logCtx, "slack outbox dead-letter failed", "error", err)
+		}
+		return
+	}
+
+	metrics.SlackPublishes.Inc(metrics.OutcomeRetry)
+	next := time.Now().Add(d.backoff(e.Attempts))
+	logger.WarnContext(logCtx, "slack outbox attempt failed, retrying", "next_attempt_at", next, "error", err)
+	if err := d.repo.MarkOutboxRetry(recordCtx, e.ID, next, err.Error()); err != nil {
+		logger.ErrorContext(logCtx, "slack outbox reschedule failed", "error", err)
+	}
+}
+
+// release returns claimed entries to the queue without recording an attempt, for the next start.
+func (d *Dispatcher) release(entries []outboxEntry) {
+	ids := make([]uuid.UUID, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.repo.ReleaseOutbox(ctx, ids); err != nil {
+		// The lease still expires, the entries are only delayed and keep the extra attempt
+		d.logger.Error("slack outbox release failed", "count", len(ids), "error", err)
+		return
+	}
+	d.logger.Info("slack outbox entries released for the next start", "count", len(ids))
+}
+
+// backoff returns an exponential delay with jitter for the given attempt number (1-based).
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	// Equal jitter: keep half the delay, randomise the rest.
+	half := delay / 2
+	return half + rand.N(half+1)
+}
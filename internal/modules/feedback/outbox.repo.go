@@ -0,0 +1,144 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"feedback/internal/logging"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// outboxEntry is a claimed slack_outbox row.
+type outboxEntry struct {
+	ID       uuid.UUID
+	Event    FeedbackEvent
+	Attempts int
+}
+
+// enqueueSlackEvent inserts a pending Slack delivery using the caller's transaction.
+// linkFeedback ties the entry to the feedback row; pass false when the row is being deleted.
+// The request ID is stored with the event so delivery logs can be traced back to the request.
+func enqueueSlackEvent(ctx context.Context, tx pgx.Tx, event FeedbackEvent, linkFeedback bool) error {
+	event.RequestID = logging.RequestID(ctx)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox payload: %w", err)
+	}
+
+	var feedbackID *string
+	if linkFeedback {
+		feedbackID = &event.FeedbackID
+	}
+
+	query := `
+		INSERT INTO slack_outbox (feedback_id, event, payload)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.Exec(ctx, query, feedbackID, event.Type, payload); err != nil {
+		return fmt.Errorf("failed to enqueue slack event: %w", err)
+	}
+	return nil
+}
+
+// ClaimOutbox leases up to limit due entries for the given duration and counts the attempt.
+// SKIP LOCKED plus the lease lets several API instances dispatch without double-sending.
+func (r *Repository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]outboxEntry, error) {
+	query := `
+		UPDATE slack_outbox
+		SET locked_until = now() + make_interval(secs => $2),
+		    attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM slack_outbox
+			WHERE status = 'pending'
+			  AND next_attempt_at <= now()
+			  AND (locked_until IS NULL OR locked_until < now())
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts
+	`
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []outboxEntry
+	for rows.Next() {
+		var e outboxEntry
+		var payload []byte
+		if err := rows.Scan(&e.ID, &payload, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		if err := json.Unmarshal(payload, &e.Event); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox payload %s: %w", e.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outbox entries: %w", err)
+	}
+	if len(entries) > 0 {
+		r.logger.DebugContext(ctx, "outbox entries claimed", "count", len(entries))
+	}
+	return entries, nil
+}
+
+// MarkOutboxSent records a successful delivery.
+func (r *Repository) MarkOutboxSent(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE slack_outbox
+		SET status = 'sent', sent_at = now(), locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox entry sent: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxRetry schedules another attempt at nextAttemptAt.
+func (r *Repository) MarkOutboxRetry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE slack_outbox
+		SET next_attempt_at = $2, locked_until = NULL, last_error = $3
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, id, nextAttemptAt, lastError); err != nil {
+		return fmt.Errorf("failed to reschedule outbox entry: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOutbox gives claimed entries back without an outcome: the lease is dropped and the
+// attempt counted by ClaimOutbox is undone, so the entries are due again right away.
+func (r *Repository) ReleaseOutbox(ctx context.Context, ids []uuid.UUID) error {
+	query := `
+		UPDATE slack_outbox
+		SET locked_until = NULL, attempts = greatest(attempts - 1, 0)
+		WHERE id = ANY($1) AND status = 'pending'
+	`
+	if _, err := r.pool.Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("failed to release outbox entries: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxDead dead-letters an entry; it is kept for inspection but never retried.
+func (r *Repository) MarkOutboxDead(ctx context.Context, id uuid.UUID, lastError string) error {
+	query := `
+		UPDATE slack_outbox
+		SET status = 'dead', locked_until = NULL, last_error = $2
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, id, lastError); err != nil {
+		return fmt.Errorf("failed to dead-letter outbox entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"feedback/internal/mail"
+
+	"github.com/google/uuid"
+)
+
+// replyExcerptRunes bounds the quote of the original feedback in reply emails.
+const replyExcerptRunes = 200
+
+// feedbackReplyEmail is the data of the feedback_reply email templates (internal/mail/templates/feedback_reply).
+type feedbackReplyEmail struct {
+	Reply    string
+	Feedback string       // one-line excerpt of the original message
+	Link     template.URL // APP_DEEPLINK_URL?feedback_id=<id>, checked by mail.Link
+}
+
+// Reply posts a public reply on the feedback and emails the submitter, the same way login
+// links are sent. The reply is stored either way; EmailSent reports whether the email went out.
+func (s *Service) Reply(ctx context.Context, id, authorID uuid.UUID, body string) (*CreateReplyResponse, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fieldError("body", "required", "is required")
+	}
+
+	reply, recipient, feedbackMessage, err := s.repo.CreateReply(ctx, id, authorID, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reply: %w", err)
+	}
+	s.logger.InfoContext(ctx, "feedback reply added", "feedback_id", id, "reply_id", reply.ID)
+
+	resp := &CreateReplyResponse{FeedbackReply: *reply}
+	if err := s.sendReplyEmail(ctx, id, recipient, feedbackMessage, body); err != nil {
+		// The reply is visible in the app; a failed email must not fail the request
+		s.logger.WarnContext(ctx, "reply email not sent", "feedback_id", id, "reply_id", reply.ID, "error", err)
+		return resp, nil
+	}
+	resp.EmailSent = true
+	return resp, nil
+}
+
+// sendReplyEmail renders the feedback_reply email in the default locale and sends it.
+func (s *Service) sendReplyEmail(ctx context.Context, id uuid.UUID, to, feedbackMessage, reply string) error {
+	if s.mailer == nil || s.templates == nil {
+		return fmt.Errorf("mail is not configured")
+	}
+	if s.cfg.DeeplinkURL == "" {
+		return fmt.Errorf("deeplink URL is not configured")
+	}
+
+	link, err := mail.Link(s.cfg.DeeplinkURL, url.Values{"feedback_id": {id.String()}})
+	if err != nil {
+		return err
+	}
+	msg, err := s.templates.Render("feedback_reply", nil, to, feedbackReplyEmail{
+		Reply:    reply,
+		Feedback: excerpt(feedbackMessage, replyExcerptRunes),
+		Link:     link,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build reply email: %w", err)
+	}
+	return s.mailer.Send(ctx, msg)
+}
+
+// excerpt collapses whitespace and shortens s to at most n runes, ending in "…" when cut.
+func excerpt(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > n {
+		return strings.TrimSpace(string(runes[:n-1])) + "…"
+	}
+	return s
+}
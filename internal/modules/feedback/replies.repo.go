@@ -0,0 +1,97 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateReply adds a reply to the feedback and records it in the audit trail. It also
+// returns the submitter's email and the feedback message for the notification.
+func (r *Repository) CreateReply(ctx context.Context, feedbackID, authorID uuid.UUID, body string) (*FeedbackReply, string, string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var recipient, message string
+	err = tx.QueryRow(ctx, `
+		SELECT u.email, f.message
+		FROM feedback f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.id = $1
+		FOR SHARE OF f
+	`, feedbackID).Scan(&recipient, &message)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", "", errFeedbackNotFound
+		}
+		return nil, "", "", fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	query := `
+		INSERT INTO feedback_replies (feedback_id, author_id, body)
+		VALUES ($1, $2, $3)
+		RETURNING id, feedback_id, author_id, (SELECT email FROM users WHERE id = $2), body, created_at
+	`
+	reply, err := scanReply(tx.QueryRow(ctx, query, feedbackID, authorID, body))
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to insert reply: %w", err)
+	}
+
+	if err := recordAudit(ctx, tx, feedbackID, authorID, AuditReplyAdded, nil, &reply.ID); err != nil {
+		return nil, "", "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", "", fmt.Errorf("failed to commit reply: %w", err)
+	}
+	return reply, recipient, message, nil
+}
+
+// scanReply scans id, feedback_id, author_id, author email, body, created_at.
+func scanReply(row pgx.Row) (*FeedbackReply, error) {
+	var reply FeedbackReply
+	var id, feedbackID uuid.UUID
+	var authorID *uuid.UUID
+	if err := row.Scan(&id, &feedbackID, &authorID, &reply.AuthorEmail, &reply.Body, &reply.CreatedAt); err != nil {
+		return nil, err
+	}
+	reply.ID = id.String()
+	reply.FeedbackID = feedbackID.String()
+	reply.AuthorID = uuidString(authorID)
+	return &reply, nil
+}
+
+// ListReplies returns the feedback's replies, oldest first.
+func (r *Repository) ListReplies(ctx context.Context, feedbackID uuid.UUID) ([]FeedbackReply, error) {
+	query := `
+		SELECT p.id, p.feedback_id, p.author_id, u.email, p.body, p.created_at
+		FROM feedback_replies p
+		LEFT JOIN users u ON u.id = p.author_id
+		WHERE p.feedback_id = $1
+		ORDER BY p.created_at ASC, p.id ASC
+	`
+	rows, err := r.pool.Query(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replies: %w", err)
+	}
+	defer rows.Close()
+
+	replies := []FeedbackReply{}
+	for rows.Next() {
+		reply, err := scanReply(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reply: %w", err)
+		}
+		replies = append(replies, *reply)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list replies: %w", err)
+	}
+	return replies, nil
+}
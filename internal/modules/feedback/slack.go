@@ -1,7 +1,58 @@
 package feedback
 
-import "context"
+import (
+	"context"
+	"log/slog"
+	"time"
+)
 
 type SlackClient interface {
-	PublishFeedback(ctx context.Context, userEmail string, message string) error
+	PublishFeedback(ctx context.Context, event FeedbackEvent) error
+}
+
+// Slack event types stored in the outbox.
+const (
+	EventFeedbackCreated = "feedback.created"
+	EventFeedbackUpdated = "feedback.updated"
+	EventFeedbackDeleted = "feedback.deleted"
+)
+
+// FeedbackEvent is a snapshot of the feedback taken when the event is enqueued.
+// It is stored as the outbox payload so delivery never depends on the current row.
+type FeedbackEvent struct {
+	Type       string    `json:"type"`
+	FeedbackID string    `json:"feedback_id"`
+	UserEmail  string    `json:"user_email"`
+	Message    string    `json:"message"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// PreviousMessage is set on feedback.updated so Slack can show what changed.
+	PreviousMessage string `json:"previous_message,omitempty"`
+
+	// RequestID is the X-Request-ID of the request that caused the event (for log correlation).
+	RequestID string `json:"request_id,omitempty"`
+
+	// Category, rating and client metadata; empty on feedback.deleted.
+	FeedbackMetadata
+}
+
+// SlackConfig selects the SlackClient used for feedback notifications.
+// An incoming webhook takes precedence over the Web API; with neither set the mock is used.
+type SlackConfig struct {
+	WebhookURL string // SLACK_WEBHOOK_URL, e.g. https://hooks.slack.com/services/T000/B000/XXXX
+	BotToken   string // SLACK_BOT_TOKEN, xoxb-… token with chat:write scope
+	Channel    string // SLACK_CHANNEL, channel ID used with chat.postMessage
+	APIBaseURL string // SLACK_API_BASE_URL, e.g. https://slack.com/api
+}
+
+// NewSlackClient returns the SlackClient selected by cfg. logger is used by the mock client.
+func NewSlackClient(cfg SlackConfig, logger *slog.Logger) SlackClient {
+	switch {
+	case cfg.WebhookURL != "":
+		return NewWebhookSlackClient(cfg.WebhookURL)
+	case cfg.BotToken != "":
+		return NewAPISlackClient(cfg.APIBaseURL, cfg.BotToken, cfg.Channel)
+	default:
+		return NewMockSlackClient(logger)
+	}
 }
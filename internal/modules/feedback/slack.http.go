@@ -0,0 +1,294 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	slackMaxRetries         = 3
+	slackDefaultRetryAfter  = time.Second
+	slackMaxRetryAfter      = 30 * time.Second
+	slackMaxSectionTextRune = 3000 // Block Kit limit for section text
+)
+
+// HTTPSlackClient posts Block Kit messages to Slack, either through an incoming
+// webhook or through the chat.postMessage Web API method.
+type HTTPSlackClient struct {
+	webhookURL string
+
+	apiBaseURL string
+	botToken   string
+	channel    string
+
+	httpClient *http.Client
+	maxRetries int
+}
+
+// NewWebhookSlackClient creates a client that posts to an incoming webhook.
+func NewWebhookSlackClient(webhookURL string) *HTTPSlackClient {
+	return &HTTPSlackClient{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		maxRetries: slackMaxRetries,
+	}
+}
+
+// NewAPISlackClient creates a client that posts with chat.postMessage using a bot token.
+func NewAPISlackClient(apiBaseURL, botToken, channel string) *HTTPSlackClient {
+	if apiBaseURL == "" {
+		apiBaseURL = "https://slack.com/api"
+	}
+	return &HTTPSlackClient{
+		apiBaseURL: strings.TrimRight(apiBaseURL, "/"),
+		botToken:   botToken,
+		channel:    channel,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		maxRetries: slackMaxRetries,
+	}
+}
+
+// slackRateLimitedError is returned when Slack answers 429 Too Many Requests.
+type slackRateLimitedError struct {
+	retryAfter time.Duration
+}
+
+func (e *slackRateLimitedError) Error() string {
+	return fmt.Sprintf("slack rate limited: retry after %s", e.retryAfter)
+}
+
+// PublishFeedback posts the feedback to Slack.
+// Rate-limited requests are retried after the Retry-After delay, up to maxRetries times.
+func (c *HTTPSlackClient) PublishFeedback(ctx context.Context, event FeedbackEvent) error {
+	payload := buildFeedbackMessage(event)
+	if c.webhookURL == "" {
+		payload.Channel = c.channel
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("slack payload encode failed: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		var rateLimited *slackRateLimitedError
+		if !errors.As(err, &rateLimited) || attempt >= c.maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(rateLimited.retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("slack publish cancelled while rate limited: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// post sends a single request to the configured endpoint.
+func (c *HTTPSlackClient) post(ctx context.Context, body []byte) error {
+	endpoint := c.webhookURL
+	if endpoint == "" {
+		endpoint = c.apiBaseURL + "/chat.postMessage"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack request build failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if c.webhookURL == "" {
+		req.Header.Set("Authorization", "Bearer "+c.botToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack publish failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &slackRateLimitedError{retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack publish failed: status=%d body=%s", resp.StatusCode, string(respBody))
+	}
+
+	// Webhooks answer with a plain "ok"; the Web API always answers 200 with an ok flag.
+	if c.webhookURL != "" {
+		return nil
+	}
+
+	var apiResp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return fmt.Errorf("slack response decode failed: %w", err)
+	}
+	if !apiResp.OK {
+		return fmt.Errorf("slack publish failed: %s", apiResp.Error)
+	}
+
+	return nil
+}
+
+// parseRetryAfter converts a Retry-After header (seconds) into a bounded delay.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return slackDefaultRetryAfter
+	}
+	d := time.Duration(seconds) * time.Second
+	if d > slackMaxRetryAfter {
+		return slackMaxRetryAfter
+	}
+	return d
+}
+
+// Block Kit payload types (only the subset we use).
+
+type slackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// buildFeedbackMessage renders a feedback event as Block Kit.
+// Text is the plain fallback shown in notifications.
+func buildFeedbackMessage(event FeedbackEvent) slackMessage {
+	title := "New feedback"
+	switch event.Type {
+	case EventFeedbackUpdated:
+		title = "Feedback edited"
+	case EventFeedbackDeleted:
+		title = "Feedback deleted"
+	}
+
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: title},
+		},
+		{
+			Type:   "section",
+			Fields: metadataFields(event),
+		},
+		{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncateMrkdwn(escapeSlackText(event.Message), slackMaxSectionTextRune)},
+		},
+	}
+
+	if len(event.Context) > 0 {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: truncateMrkdwn(contextText(event.Context), slackMaxSectionTextRune)},
+		})
+	}
+
+	if event.Type == EventFeedbackUpdated && event.PreviousMessage != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: "*Previously:*\n" + truncateMrkdwn(escapeSlackText(event.PreviousMessage), slackMaxSectionTextRune-20)},
+		})
+	}
+
+	blocks = append(blocks, slackBlock{
+		Type: "context",
+		Elements: []slackText{
+			{Type: "mrkdwn", Text: fmt.Sprintf("ID `%s` · <!date^%d^{date_short_pretty} {time}|%s>",
+				event.FeedbackID, event.OccurredAt.Unix(), event.OccurredAt.UTC().Format(time.RFC3339))},
+		},
+	})
+
+	return slackMessage{
+		Text:   fmt.Sprintf("%s from %s", title, event.UserEmail),
+		Blocks: blocks,
+	}
+}
+
+// metadataFields lists the sender and whichever metadata the feedback carries.
+func metadataFields(event FeedbackEvent) []slackText {
+	fields := []slackText{{Type: "mrkdwn", Text: "*From:*\n" + escapeSlackText(event.UserEmail)}}
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, slackText{Type: "mrkdwn", Text: "*" + label + ":*\n" + escapeSlackText(value)})
+		}
+	}
+
+	add("Category", event.Category)
+	if event.Rating != nil {
+		add("Rating", strings.Repeat("★", *event.Rating)+strings.Repeat("☆", 5-*event.Rating))
+	}
+	add("Platform", strings.TrimSpace(event.Platform+" "+event.OSVersion))
+	add("App version", event.AppVersion)
+	return fields
+}
+
+// contextText renders the context map as one "key: value" line per entry, sorted by key.
+func contextText(entries map[string]string) string {
+	var b strings.Builder
+	b.WriteString("*Context:*")
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		fmt.Fprintf(&b, "\n`%s`: %s", escapeSlackText(key), escapeSlackText(entries[key]))
+	}
+	return b.String()
+}
+
+// escapeSlackText escapes the control characters Slack uses for mrkdwn markup.
+func escapeSlackText(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncateMrkdwn shortens escaped mrkdwn to at most max runes, marking the cut with an
+// ellipsis. Escape first so the limit holds for what Slack receives; the cut never splits
+// an entity such as &amp;.
+func truncateMrkdwn(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	runes = runes[:max-1]
+	// Entities are at most 5 runes ("&amp;"), so an unterminated one starts in the last 4
+	for i := len(runes) - 1; i >= 0 && i >= len(runes)-4; i-- {
+		if runes[i] == ';' {
+			break
+		}
+		if runes[i] == '&' {
+			runes = runes[:i]
+			break
+		}
+	}
+	return string(runes) + "…"
+}
@@ -2,21 +2,28 @@ package feedback
 
 import (
 	"context"
-	"log"
+	"log/slog"
+	"unicode/utf8"
 )
 
 // MockSlackClient is a mock implementation of SlackClient for testing/development.
 // It logs messages instead of sending them to Slack.
-type MockSlackClient struct{}
+type MockSlackClient struct {
+	logger *slog.Logger
+}
 
 // NewMockSlackClient creates a new mock Slack client.
-func NewMockSlackClient() *MockSlackClient {
-	return &MockSlackClient{}
+func NewMockSlackClient(logger *slog.Logger) *MockSlackClient {
+	return &MockSlackClient{logger: logger}
 }
 
-// PublishFeedback logs the feedback message instead of sending to Slack.
-// Always returns nil for predictable behavior.
-func (m *MockSlackClient) PublishFeedback(ctx context.Context, userEmail string, message string) error {
-	log.Printf("[MOCK SLACK] User: %s, Message: %s", userEmail, message)
+// PublishFeedback logs the feedback message instead of sending to Slack; the message and
+// submitter email are logged at Debug only. Always returns nil for predictable behavior.
+func (m *MockSlackClient) PublishFeedback(ctx context.Context, event FeedbackEvent) error {
+	m.logger.InfoContext(ctx, "mock slack publish",
+		"event", event.Type, "feedback_id", event.FeedbackID, "message_runes", utf8.RuneCountInString(event.Message),
+		"category", event.Category, "platform", event.Platform)
+	m.logger.DebugContext(ctx, "mock slack message",
+		"feedback_id", event.FeedbackID, "user_email", event.UserEmail, "message", event.Message)
 	return nil
 }
@@ -0,0 +1,107 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// statusTransitions lists the statuses each status may move to. Feedback normally goes
+// new → triaged → in_progress → resolved; open items may be closed as wont_fix, resolved
+// ones reopened (in_progress) and wont_fix ones reconsidered (triaged).
+var statusTransitions = map[string][]string{
+	StatusNew:        {StatusTriaged, StatusWontFix},
+	StatusTriaged:    {StatusInProgress, StatusResolved, StatusWontFix},
+	StatusInProgress: {StatusTriaged, StatusResolved, StatusWontFix},
+	StatusResolved:   {StatusInProgress},
+	StatusWontFix:    {StatusTriaged},
+}
+
+// statusesLeadingTo returns the statuses from which feedback may move to status.
+func statusesLeadingTo(status string) []string {
+	var from []string
+	for s, next := range statusTransitions {
+		if slices.Contains(next, status) {
+			from = append(from, s)
+		}
+	}
+	slices.Sort(from)
+	return from
+}
+
+// ChangeStatus moves feedback to status, recording the change in the audit trail.
+// Setting the current status again is a no-op; a transition not in statusTransitions
+// fails with errInvalidTransition, whose details list the statuses allowed next.
+func (s *Service) ChangeStatus(ctx context.Context, id, actorID uuid.UUID, status string) (*AdminFeedback, error) {
+	if _, ok := statusTransitions[status]; !ok {
+		return nil, fieldError("status", "invalid_value", "must be one of: new, triaged, in_progress, resolved, wont_fix")
+	}
+
+	from, err := s.repo.SetStatus(ctx, id, actorID, status, statusesLeadingTo(status))
+	if err != nil {
+		if errors.Is(err, errInvalidTransition) {
+			return nil, errInvalidTransition.WithDetails(map[string]any{
+				"from":    from,
+				"to":      status,
+				"allowed": statusTransitions[from],
+			})
+		}
+		return nil, fmt.Errorf("failed to change feedback status: %w", err)
+	}
+	if from != status {
+		s.logger.InfoContext(ctx, "feedback status changed", "feedback_id", id, "from", from, "to", status)
+	}
+
+	return s.getAny(ctx, id)
+}
+
+// Assign makes the admin assigneeID responsible for the feedback.
+func (s *Service) Assign(ctx context.Context, id, actorID uuid.UUID, assigneeID string) (*AdminFeedback, error) {
+	assignee, err := uuid.Parse(assigneeID)
+	if err != nil {
+		return nil, errInvalidAssignee
+	}
+
+	if err := s.repo.SetAssignee(ctx, id, actorID, &assignee); err != nil {
+		return nil, fmt.Errorf("failed to assign feedback: %w", err)
+	}
+	s.logger.InfoContext(ctx, "feedback assigned", "feedback_id", id, "assignee_id", assignee)
+
+	return s.getAny(ctx, id)
+}
+
+// Unassign removes the feedback's assignee, if any.
+func (s *Service) Unassign(ctx context.Context, id, actorID uuid.UUID) (*AdminFeedback, error) {
+	if err := s.repo.SetAssignee(ctx, id, actorID, nil); err != nil {
+		return nil, fmt.Errorf("failed to unassign feedback: %w", err)
+	}
+	return s.getAny(ctx, id)
+}
+
+// AddNote adds an internal note to the feedback. Notes are only returned by admin endpoints.
+func (s *Service) AddNote(ctx context.Context, id, authorID uuid.UUID, body string) (*FeedbackNote, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fieldError("body", "required", "is required")
+	}
+
+	note, err := s.repo.CreateNote(ctx, id, authorID, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add note: %w", err)
+	}
+	s.logger.InfoContext(ctx, "feedback note added", "feedback_id", id, "note_id", note.ID)
+	return note, nil
+}
+
+// getAny re-reads feedback after a triage change.
+func (s *Service) getAny(ctx context.Context, id uuid.UUID) (*AdminFeedback, error) {
+	f, err := s.repo.GetAny(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+	return f, nil
+}
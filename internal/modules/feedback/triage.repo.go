@@ -0,0 +1,228 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// recordAudit appends one entry to the feedback's audit trail inside tx.
+func recordAudit(ctx context.Context, tx pgx.Tx, feedbackID, actorID uuid.UUID, action string, from, to *string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO feedback_audit (feedback_id, feedback_ref, actor_id, action, from_value, to_value)
+		VALUES ($1, $1, $2, $3, $4, $5)
+	`, feedbackID, actorID, action, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// SetStatus locks the feedback row and moves it to status when its current status is one
+// of allowedFrom, recording the change. It returns the status the feedback had; when that
+// is not in allowedFrom the error is errInvalidTransition.
+func (r *Repository) SetStatus(ctx context.Context, id, actorID uuid.UUID, status string, allowedFrom []string) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var from string
+	err = tx.QueryRow(ctx, `SELECT status FROM feedback WHERE id = $1 FOR UPDATE`, id).Scan(&from)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", errFeedbackNotFound
+		}
+		return "", fmt.Errorf("failed to lock feedback: %w", err)
+	}
+
+	if from == status {
+		return from, nil
+	}
+	if !slices.Contains(allowedFrom, from) {
+		return from, errInvalidTransition
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE feedback SET status = $2 WHERE id = $1`, id, status); err != nil {
+		return "", fmt.Errorf("failed to update feedback status: %w", err)
+	}
+	if err := recordAudit(ctx, tx, id, actorID, AuditStatusChanged, &from, &status); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to commit status change: %w", err)
+	}
+	return from, nil
+}
+
+// SetAssignee assigns the feedback to assigneeID, which must be an admin, or unassigns it
+// when assigneeID is nil, recording the change. Setting the current assignee again is a no-op.
+func (r *Repository) SetAssignee(ctx context.Context, id, actorID uuid.UUID, assigneeID *uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if assigneeID != nil {
+		var role string
+		err := tx.QueryRow(ctx, `SELECT role FROM users WHERE id = $1`, *assigneeID).Scan(&role)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && role != "admin") {
+			return errInvalidAssignee
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get assignee: %w", err)
+		}
+	}
+
+	var current *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT assignee_id FROM feedback WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errFeedbackNotFound
+		}
+		return fmt.Errorf("failed to lock feedback: %w", err)
+	}
+
+	if (current == nil && assigneeID == nil) || (current != nil && assigneeID != nil && *current == *assigneeID) {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE feedback SET assignee_id = $2 WHERE id = $1`, id, assigneeID); err != nil {
+		return fmt.Errorf("failed to update feedback assignee: %w", err)
+	}
+
+	action := AuditAssigned
+	if assigneeID == nil {
+		action = AuditUnassigned
+	}
+	if err := recordAudit(ctx, tx, id, actorID, action, uuidString(current), uuidString(assigneeID)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit assignment: %w", err)
+	}
+	return nil
+}
+
+// uuidString formats an optional UUID for the audit trail.
+func uuidString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+// CreateNote adds an internal note to the feedback and records it in the audit trail.
+func (r *Repository) CreateNote(ctx context.Context, feedbackID, authorID uuid.UUID, body string) (*FeedbackNote, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Inserting nothing means the feedback does not exist (or was deleted meanwhile)
+	query := `
+		INSERT INTO feedback_notes (feedback_id, author_id, body)
+		SELECT id, $2, $3 FROM feedback WHERE id = $1
+		RETURNING id, feedback_id, author_id, (SELECT email FROM users WHERE id = $2), body, created_at
+	`
+	note, err := scanNote(tx.QueryRow(ctx, query, feedbackID, authorID, body))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errFeedbackNotFound
+		}
+		return nil, fmt.Errorf("failed to insert note: %w", err)
+	}
+
+	if err := recordAudit(ctx, tx, feedbackID, authorID, AuditNoteAdded, nil, &note.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit note: %w", err)
+	}
+	return note, nil
+}
+
+// scanNote scans id, feedback_id, author_id, author email, body, created_at.
+func scanNote(row pgx.Row) (*FeedbackNote, error) {
+	var n FeedbackNote
+	var id, feedbackID uuid.UUID
+	var authorID *uuid.UUID
+	if err := row.Scan(&id, &feedbackID, &authorID, &n.AuthorEmail, &n.Body, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	n.ID = id.String()
+	n.FeedbackID = feedbackID.String()
+	n.AuthorID = uuidString(authorID)
+	return &n, nil
+}
+
+// ListNotes returns the feedback's internal notes, oldest first.
+func (r *Repository) ListNotes(ctx context.Context, feedbackID uuid.UUID) ([]FeedbackNote, error) {
+	query := `
+		SELECT n.id, n.feedback_id, n.author_id, u.email, n.body, n.created_at
+		FROM feedback_notes n
+		LEFT JOIN users u ON u.id = n.author_id
+		WHERE n.feedback_id = $1
+		ORDER BY n.created_at ASC, n.id ASC
+	`
+	rows, err := r.pool.Query(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []FeedbackNote{}
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan note: %w", err)
+		}
+		notes = append(notes, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+	return notes, nil
+}
+
+// ListAudit returns the feedback's audit trail, oldest first.
+func (r *Repository) ListAudit(ctx context.Context, feedbackID uuid.UUID) ([]AuditEntry, error) {
+	query := `
+		SELECT a.action, a.actor_id, u.email, a.from_value, a.to_value, a.created_at
+		FROM feedback_audit a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.feedback_ref = $1
+		ORDER BY a.created_at ASC, a.id ASC
+	`
+	rows, err := r.pool.Query(ctx, query, feedbackID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit trail: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var actorID *uuid.UUID
+		if err := rows.Scan(&e.Action, &actorID, &e.ActorEmail, &e.From, &e.To, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.ActorID = uuidString(actorID)
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit trail: %w", err)
+	}
+	return entries, nil
+}
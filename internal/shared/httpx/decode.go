@@ -0,0 +1,86 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes caps request bodies when DecodeJSON is given no limit.
+const DefaultMaxBodyBytes int64 = 64 << 10
+
+var (
+	ErrUnsupportedMediaType = NewError(http.StatusUnsupportedMediaType, "unsupported_media_type", "The request body must be sent as application/json.")
+	ErrUnknownField         = NewError(http.StatusBadRequest, "unknown_field", "The request body contains an unknown field.")
+)
+
+// DecodeJSON decodes the request body into dst and validates it (see Validate).
+// The body must be a single application/json object of at most maxBytes
+// (DefaultMaxBodyBytes when maxBytes <= 0) without fields dst does not declare.
+// The returned error is an *Error ready for WriteErr.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any, maxBytes int64) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return ErrUnsupportedMediaType
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	// Reject trailing data such as a second object
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			return ErrInvalidJSON.WithDetails(map[string]string{"reason": "body must contain a single JSON object"})
+		}
+		return decodeError(err)
+	}
+
+	return Validate(dst)
+}
+
+// decodeError maps a json.Decoder error to an API error.
+func decodeError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return ErrRequestTooLarge.Wrap(err)
+	case errors.As(err, &typeErr):
+		return ErrValidation.WithDetails(ValidationDetails{Fields: []FieldError{{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: "must be a " + jsonTypeName(typeErr.Type.Kind().String()),
+		}}})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for DisallowUnknownFields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return ErrUnknownField.WithDetails(map[string]string{"field": field})
+	default:
+		return ErrInvalidJSON.Wrap(err)
+	}
+}
+
+// jsonTypeName names a Go kind the way API clients know it.
+func jsonTypeName(kind string) string {
+	switch kind {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "slice", "array":
+		return "array"
+	case "struct", "map":
+		return "object"
+	default:
+		return "number"
+	}
+}
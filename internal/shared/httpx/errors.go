@@ -0,0 +1,100 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Error is an API error: a stable machine-readable code, the HTTP status it maps to,
+// a human-readable message and optional details. Modules declare their errors as
+// sentinels (e.g. errFeedbackNotFound) and handlers write them with WriteErr.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	Details any
+
+	// cause is the underlying error, kept for logs and never sent to the client.
+	cause error
+}
+
+// NewError creates an API error sentinel.
+func NewError(status int, code, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Code + ": " + e.cause.Error()
+	}
+	return e.Code
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is matches errors with the same code, so copies made by WithDetails and Wrap
+// still satisfy errors.Is(err, sentinel).
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of e carrying details (serialized as "details").
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Wrap returns a copy of e recording cause for logs.
+func (e *Error) Wrap(cause error) *Error {
+	c := *e
+	c.cause = cause
+	return &c
+}
+
+// Errors shared by every module.
+var (
+	ErrInternal         = NewError(http.StatusInternalServerError, "internal_error", "Something went wrong on our side. Please try again.")
+	ErrMethodNotAllowed = NewError(http.StatusMethodNotAllowed, "method_not_allowed", "This method is not supported for this endpoint.")
+	ErrInvalidJSON      = NewError(http.StatusBadRequest, "invalid_json", "The request body is not valid JSON.")
+	ErrRequestTooLarge  = NewError(http.StatusRequestEntityTooLarge, "request_too_large", "The request body is too large.")
+)
+
+// ErrorResponse is the JSON body of every error response.
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+	Details   any    `json:"details,omitempty"`
+}
+
+// requestIDHeader is set on the response by middleware.RequestID before handlers run.
+const requestIDHeader = "X-Request-ID"
+
+// WriteErr writes err as a JSON error response. Errors wrapping an *Error use its status,
+// code, message and details; anything else is reported as a 500 internal_error without
+// exposing the underlying message.
+func WriteErr(w http.ResponseWriter, err error) {
+	apiErr := ErrInternal
+	var e *Error
+	if errors.As(err, &e) {
+		apiErr = e
+	}
+
+	WriteJSON(w, apiErr.Status, ErrorResponse{
+		Error:     apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: w.Header().Get(requestIDHeader),
+		Details:   apiErr.Details,
+	})
+}
+
+// IsServerError reports whether WriteErr would answer err with a 5xx status,
+// i.e. whether it is unexpected and worth logging.
+func IsServerError(err error) bool {
+	var e *Error
+	return !errors.As(err, &e) || e.Status >= http.StatusInternalServerError
+}
@@ -0,0 +1,29 @@
+package httpx
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client that sent r.
+// With trustProxy set, the right-most X-Forwarded-For entry (the one appended by our
+// proxy, e.g. Render) is used when present. Earlier entries are client-controlled, including
+// whole header lines: a proxy may append its own line rather than join the client's, so all
+// lines are read as one list.
+func ClientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := strings.Join(r.Header.Values("X-Forwarded-For"), ","); fwd != "" {
+			entries := strings.Split(fwd, ",")
+			if ip := strings.TrimSpace(entries[len(entries)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -12,7 +12,8 @@ func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// WriteError writes a JSON error response.
-func WriteError(w http.ResponseWriter, status int, errorCode string) {
-	WriteJSON(w, status, map[string]string{"error": errorCode})
+// MethodNotAllowed is a fallback handler for routes registered with method patterns,
+// so unsupported methods get the JSON error envelope instead of the mux's plain text.
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	WriteErr(w, ErrMethodNotAllowed)
 }
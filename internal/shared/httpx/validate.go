@@ -0,0 +1,210 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrValidation is returned by Validate; its details list every invalid field.
+var ErrValidation = NewError(http.StatusBadRequest, "validation_failed", "One or more fields are invalid.")
+
+// FieldError describes why one request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`   // JSON name of the field
+	Code    string `json:"code"`    // required, invalid_email, too_short, too_long, invalid_length, invalid_value, too_small, too_large, too_few, too_many, invalid_type
+	Message string `json:"message"` // human-readable, e.g. "must be at most 254 characters"
+}
+
+// ValidationDetails is the "details" object of a validation_failed error.
+type ValidationDetails struct {
+	Fields []FieldError `json:"fields"`
+}
+
+// Validate checks the `validate` struct tags of v (a struct or pointer to one) and returns
+// ErrValidation listing the first failed rule of every invalid field, or nil.
+// Fields of embedded structs are checked as if they were declared on v.
+//
+// Rules, comma separated:
+//
+//	required   non-blank string (whitespace only counts as blank) or non-zero value
+//	email      a bare address such as user@example.com
+//	min=N      strings: at least N characters (runes); numbers: at least N; maps and slices: at least N entries
+//	max=N      strings: at most N characters (runes); numbers: at most N; maps and slices: at most N entries
+//	len=N      exactly N characters (runes)
+//	oneof=a b  one of the space-separated values
+//
+// Rules other than required are skipped for empty strings and nil pointers, so optional
+// fields may be omitted.
+func Validate(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	if fields := validateStruct(rv); len(fields) > 0 {
+		return ErrValidation.WithDetails(ValidationDetails{Fields: fields})
+	}
+	return nil
+}
+
+// validateStruct validates the tagged fields of rv, descending into embedded structs.
+func validateStruct(rv reflect.Value) []FieldError {
+	var fields []FieldError
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, validateStruct(rv.Field(i))...)
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "" || !sf.IsExported() {
+			continue
+		}
+		if fe, ok := validateField(jsonName(sf), rv.Field(i), tag); !ok {
+			fields = append(fields, fe)
+		}
+	}
+	return fields
+}
+
+// validateField applies the rules in tag to one field, stopping at the first failure.
+func validateField(name string, fv reflect.Value, tag string) (FieldError, bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		key, arg, _ := strings.Cut(rule, "=")
+
+		if key == "required" {
+			if fv.IsZero() || (fv.Kind() == reflect.String && strings.TrimSpace(fv.String()) == "") {
+				return FieldError{name, "required", "is required"}, false
+			}
+			continue
+		}
+
+		v := fv
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+
+		var fe FieldError
+		var ok bool
+		switch v.Kind() {
+		case reflect.String:
+			if v.String() == "" {
+				continue
+			}
+			fe, ok = checkString(name, v.String(), rule, key, arg)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fe, ok = checkNumber(name, v.Int(), rule, key, arg)
+		case reflect.Map, reflect.Slice:
+			fe, ok = checkCount(name, v.Len(), rule, key, arg)
+		default:
+			panic(fmt.Sprintf("httpx: rule %q on unsupported field %s", rule, name))
+		}
+		if !ok {
+			return fe, false
+		}
+	}
+	return FieldError{}, true
+}
+
+// checkString applies a string rule.
+func checkString(name, s, rule, key, arg string) (FieldError, bool) {
+	runes := utf8.RuneCountInString(s)
+	switch key {
+	case "email":
+		if !validEmail(s) {
+			return FieldError{name, "invalid_email", "must be a valid email address"}, false
+		}
+	case "min":
+		if runes < ruleInt(name, rule, arg) {
+			return FieldError{name, "too_short", "must be at least " + arg + " characters"}, false
+		}
+	case "max":
+		if runes > ruleInt(name, rule, arg) {
+			return FieldError{name, "too_long", "must be at most " + arg + " characters"}, false
+		}
+	case "len":
+		if runes != ruleInt(name, rule, arg) {
+			return FieldError{name, "invalid_length", "must be exactly " + arg + " characters"}, false
+		}
+	case "oneof":
+		options := strings.Fields(arg)
+		if !slices.Contains(options, s) {
+			return FieldError{name, "invalid_value", "must be one of: " + strings.Join(options, ", ")}, false
+		}
+	default:
+		panic(fmt.Sprintf("httpx: unknown string rule %q on field %s", rule, name))
+	}
+	return FieldError{}, true
+}
+
+// checkNumber applies a min/max rule to an integer.
+func checkNumber(name string, n int64, rule, key, arg string) (FieldError, bool) {
+	switch key {
+	case "min":
+		if n < int64(ruleInt(name, rule, arg)) {
+			return FieldError{name, "too_small", "must be at least " + arg}, false
+		}
+	case "max":
+		if n > int64(ruleInt(name, rule, arg)) {
+			return FieldError{name, "too_large", "must be at most " + arg}, false
+		}
+	default:
+		panic(fmt.Sprintf("httpx: unknown number rule %q on field %s", rule, name))
+	}
+	return FieldError{}, true
+}
+
+// checkCount applies a min/max rule to the number of entries in a map or slice.
+func checkCount(name string, n int, rule, key, arg string) (FieldError, bool) {
+	switch key {
+	case "min":
+		if n < ruleInt(name, rule, arg) {
+			return FieldError{name, "too_few", "must have at least " + arg + " entries"}, false
+		}
+	case "max":
+		if n > ruleInt(name, rule, arg) {
+			return FieldError{name, "too_many", "must have at most " + arg + " entries"}, false
+		}
+	default:
+		panic(fmt.Sprintf("httpx: unknown count rule %q on field %s", rule, name))
+	}
+	return FieldError{}, true
+}
+
+// validEmail accepts a bare address (no display name) with a dotted domain.
+func validEmail(s string) bool {
+	s = strings.TrimSpace(s)
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	_, domain, _ := strings.Cut(s, "@")
+	return strings.Contains(domain, ".")
+}
+
+// ruleInt parses a rule argument; tags are fixed at compile time, so a bad one panics.
+func ruleInt(name, rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic(fmt.Sprintf("httpx: bad validation rule %q on field %s", rule, name))
+	}
+	return n
+}
+
+// jsonName is the field's name in the JSON body.
+func jsonName(sf reflect.StructField) string {
+	if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return sf.Name
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Store keeps blobs (such as feedback attachments) under slash-separated keys.
+// Implementations are selected by Config.Backend.
+type Store interface {
+	// Put stores the content of r under key, replacing any existing blob.
+	Put(ctx context.Context, key, contentType string, r io.Reader) error
+	// Open returns the blob stored under key, or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key; deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrNotFound is returned by Open when no blob is stored under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Storage backends accepted in Config.Backend (STORAGE_BACKEND).
+const (
+	BackendLocal = "local"
+)
+
+// Config selects and configures the Store returned by New.
+type Config struct {
+	Backend string // STORAGE_BACKEND: local (an S3-compatible backend can be added here)
+	Dir     string // STORAGE_DIR, root directory of the local backend
+}
+
+// New returns the Store selected by cfg.Backend.
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case BackendLocal:
+		return NewLocalStore(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+
+// validKey accepts keys of safe path segments, so a key can never escape the store root.
+func validKey(key string) error {
+	if !keyPattern.MatchString(key) || strings.Contains(key, "..") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}